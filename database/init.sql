@@ -101,8 +101,17 @@ CREATE TABLE games (
         CHECK (phase IN ('START', 'MOVE', 'MAIN', 'ATTACK', 'END')),
     active_player UUID NOT NULL, -- References either player1_id or player2_id
     game_state JSONB NOT NULL DEFAULT '{}', -- Complete game state
+    version BIGINT NOT NULL DEFAULT 0, -- game_state version, compared on every save (optimistic locking)
+    seed BIGINT, -- random seed; with the decks and game_actions it rebuilds any point of the game
+    player1_deck JSONB, -- player 1 deck as submitted, before shuffling
+    player2_deck JSONB, -- player 2 deck as submitted, before shuffling
+    time_control JSONB, -- per-turn and reserve time limits; NULL for untimed games
     winner UUID REFERENCES users(id), -- NULL for ongoing games
+    end_reason VARCHAR(20) CHECK (end_reason IN ('NORMAL_WIN', 'SURRENDER', 'TIMEOUT', 'DECK_OUT', 'CONNECTION_LOST', 'DRAW')), -- NULL for ongoing games
+    result_pending BOOLEAN NOT NULL DEFAULT false, -- completed, but the result has not reached the result service yet
     game_mode VARCHAR(20) DEFAULT 'RANKED' CHECK (game_mode IN ('RANKED', 'CASUAL', 'FRIEND')),
+    allow_spectators BOOLEAN NOT NULL DEFAULT true, -- players can opt out per game (e.g. friend games)
+    spectator_delay INTEGER NOT NULL DEFAULT 0 CHECK (spectator_delay >= 0), -- spectators see the game this many actions late
     started_at TIMESTAMP WITH TIME ZONE,
     completed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
@@ -117,8 +126,9 @@ CREATE TABLE game_actions (
     game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
     player_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     action_type VARCHAR(30) NOT NULL CHECK (action_type IN (
-        'DRAW_CARD', 'PLAY_CARD', 'ATTACK', 'BLOCK', 'ACTIVATE_EFFECT',
-        'MOVE_CHARACTER', 'END_PHASE', 'END_TURN', 'SURRENDER'
+        'DRAW_CARD', 'EXTRA_DRAW', 'PLAY_CARD', 'ATTACK', 'BLOCK', 'ACTIVATE_EFFECT',
+        'MOVE_CHARACTER', 'END_PHASE', 'END_TURN', 'SURRENDER', 'RESOLVE_DECISION', 'MULLIGAN',
+        'TIMEOUT', 'CONNECTION_LOST'
     )),
     action_data JSONB NOT NULL DEFAULT '{}',
     turn INTEGER NOT NULL CHECK (turn >= 1),
@@ -126,7 +136,7 @@ CREATE TABLE game_actions (
     timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     is_valid BOOLEAN DEFAULT true,
     error_msg TEXT,
-    sequence_number INTEGER NOT NULL, -- For action ordering
+    sequence_number INTEGER NOT NULL, -- For action ordering: the game_state version after the action
     UNIQUE(game_id, sequence_number)
 );
 
@@ -270,6 +280,8 @@ CREATE INDEX idx_games_status ON games(status);
 CREATE INDEX idx_games_active_player ON games(active_player) WHERE status = 'IN_PROGRESS';
 CREATE INDEX idx_games_created_at ON games(created_at DESC);
 CREATE INDEX idx_games_mode ON games(game_mode);
+CREATE INDEX idx_games_spectatable ON games(started_at DESC) WHERE status = 'IN_PROGRESS' AND allow_spectators;
+CREATE INDEX idx_games_result_pending ON games(completed_at) WHERE result_pending;
 
 -- Game actions indexes (partitioned by game_id for performance)
 CREATE INDEX idx_actions_game ON game_actions(game_id, sequence_number);
@@ -278,7 +290,7 @@ CREATE INDEX idx_actions_timestamp ON game_actions(timestamp DESC);
 CREATE INDEX idx_actions_type ON game_actions(action_type);
 
 -- Game results indexes
-CREATE INDEX idx_results_game ON game_results(game_id);
+CREATE UNIQUE INDEX idx_results_game ON game_results(game_id); -- a game has one result, recorded once
 CREATE INDEX idx_results_players ON game_results(player1_id, player2_id);
 CREATE INDEX idx_results_winner ON game_results(winner) WHERE winner IS NOT NULL;
 CREATE INDEX idx_results_completed_at ON game_results(completed_at DESC);
@@ -490,6 +502,13 @@ INSERT INTO achievements (name, description, type, condition, reward) VALUES
 ('Perfect Week', 'Win 7 games without losing in 7 days', 'STREAK', '{"type": "perfect_week", "value": 7}', '{"experience": 350, "title": "Perfect Week"}'),
 ('Deck Master', 'Win with 10 different deck compositions', 'SPECIAL', '{"type": "deck_variety", "value": 10}', '{"experience": 300, "deck_slot": 1}');
 
+-- Built-in bot players for practice games (see game-battle-service internal/bot)
+-- The password hash is not a valid bcrypt hash, so these accounts can never log in
+INSERT INTO users (id, username, email, password_hash, display_name) VALUES
+('00000000-0000-4000-8000-00000000b001', 'bot_random', 'bot_random@bots.local', '!', 'Practice Bot (Random)'),
+('00000000-0000-4000-8000-00000000b002', 'bot_greedy', 'bot_greedy@bots.local', '!', 'Practice Bot (Greedy)'),
+('00000000-0000-4000-8000-00000000b003', 'bot_lookahead', 'bot_lookahead@bots.local', '!', 'Practice Bot (Lookahead)');
+
 -- Sample work codes and their themes
 COMMENT ON COLUMN cards.work_code IS 'Work series codes: UA25BT (25th Booster), UA25ST (25th Starter), etc.';
 COMMENT ON COLUMN cards.card_variant_id IS 'Unique identifier combining card number and rarity (e.g., UA25BT-001-SR★★★)';
@@ -448,17 +448,12 @@ func (s *cardService) ValidateCardPlay(ctx context.Context, req *ValidateCardPla
 		validation.Errors = append(validation.Errors, fmt.Sprintf("Insufficient AP: required %d, have %d", card.APCost, player.AP))
 	}
 
-	var energyCost map[string]int
-	if card.EnergyCost != nil {
-		if err := json.Unmarshal(card.EnergyCost, &energyCost); err == nil {
-			validation.RequiredEnergy = energyCost
-			for color, required := range energyCost {
-				if player.Energy[color] < required {
-					validation.IsValid = false
-					validation.Errors = append(validation.Errors, fmt.Sprintf("Insufficient %s energy: required %d, have %d", color, required, player.Energy[color]))
-				}
-			}
-		}
+	// Energy comes from the cards the player has in play, using the same rules as the battle engine
+	energyCost := models.ParseEnergy(card.EnergyCost)
+	validation.RequiredEnergy = energyCost
+	for _, shortage := range models.CheckEnergyRequirement(player.AvailableEnergy(), energyCost) {
+		validation.IsValid = false
+		validation.Errors = append(validation.Errors, fmt.Sprintf("Insufficient %s energy: required %d, have %d", shortage.Color, shortage.Required, shortage.Available))
 	}
 
 	effects, err := s.cardRepo.GetCardEffects(ctx, req.CardID)
@@ -593,28 +588,20 @@ func (s *cardService) GetRarityTiers(ctx context.Context) (map[string]int, error
 func (s *cardService) parseKeywords(keywords []string) []KeywordRule {
 	var rules []KeywordRule
 
-	keywordDefinitions := map[string]KeywordRule{
-		"レイド": {
-			Keyword:     "レイド",
-			Description: "Attack multiple targets",
-			Parameters:  map[string]interface{}{"targets": 2},
-		},
-		"狙い撃ち": {
-			Keyword:     "狙い撃ち",
-			Description: "Can target specific positions",
-			Parameters:  map[string]interface{}{"precision": true},
-		},
-		"ダメージ": {
-			Keyword:     "ダメージ",
-			Description: "Deal damage to target",
-			Parameters:  map[string]interface{}{"base_damage": 1},
-		},
-	}
-
-	for _, keyword := range keywords {
-		if rule, exists := keywordDefinitions[keyword]; exists {
-			rules = append(rules, rule)
+	// Definitions and parameter parsing come from the shared catalogue used by the battle engine
+	for _, keyword := range models.ParseKeywords(keywords) {
+		definition, _ := models.LookupKeyword(keyword.Name)
+
+		parameters := map[string]interface{}{}
+		if definition.HasValue {
+			parameters["value"] = keyword.Value
 		}
+
+		rules = append(rules, KeywordRule{
+			Keyword:     keyword.Name,
+			Description: definition.Description,
+			Parameters:  parameters,
+		})
 	}
 
 	return rules
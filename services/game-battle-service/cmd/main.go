@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,12 +15,14 @@ import (
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 	_ "ua/services/game-battle-service/docs" // Swagger docs
+	"ua/services/game-battle-service/internal/bot"
 	"ua/services/game-battle-service/internal/engine"
 	"ua/services/game-battle-service/internal/handler"
 	"ua/services/game-battle-service/internal/repository"
 	"ua/services/game-battle-service/internal/service"
 	"ua/shared/config"
 	"ua/shared/database"
+	"ua/shared/events"
 	"ua/shared/logger"
 	"ua/shared/middleware"
 	"ua/shared/redis"
@@ -55,6 +58,10 @@ func main() {
 		log.Fatal("Failed to initialize logger:", err)
 	}
 
+	if cfg.GameEventsSecret == "" {
+		log.Fatal("GAME_EVENTS_SECRET must be set")
+	}
+
 	db, err := database.NewPostgresDB(cfg.PostgresURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
@@ -68,13 +75,30 @@ func main() {
 	defer redisClient.Close()
 
 	gameRepo := repository.NewGameRepository(db, redisClient)
-	gameEngine := engine.NewGameEngine()
-	gameService := service.NewGameService(gameRepo, gameEngine)
-	gameHandler := handler.NewGameHandler(gameService)
+	gameEngine := engine.NewGameEngine(rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), rand.Uint64())), engine.SystemClock{})
+	bots := bot.NewRoster(gameEngine, rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), rand.Uint64())))
 
 	// Initialize WebSocket Hub
 	wsHub := websocket.NewHub()
+	if config.GetEnvBool("WEBSOCKET_REDIS_FANOUT", false) {
+		// Lets every replica deliver to clients connected to any other one, and see players connected to it
+		wsHub.SetBackend(websocket.NewRedisBackend(redisClient, "ws:game-battle"))
+		wsHub.SetConnections(websocket.NewRedisConnections(redisClient, "websocket:connections"))
+	}
+
+	// Finished games are reported to the result service, signed with the game events secret both services share
+	resultPublisher := events.NewPublisher(redisClient, cfg.GameEventsSecret)
+
+	gameService := service.NewGameService(gameRepo, gameEngine, bots, wsHub, resultPublisher)
+	wsHub.SetPresenceHandler(gameService)
+	wsHub.SetRequestHandler(handler.NewSocketHandler(gameService))
 	go wsHub.Run()
+	gameHandler := handler.NewGameHandler(gameService, wsHub)
+
+	// Turn timers and reconnect windows run until shutdown
+	timersCtx, stopTimers := context.WithCancel(context.Background())
+	defer stopTimers()
+	go gameService.RunTimers(timersCtx)
 
 	router := setupRouter(cfg, gameHandler, wsHub)
 
@@ -95,6 +119,7 @@ func main() {
 	<-quit
 
 	logger.Info("Game Battle Service shutting down...")
+	stopTimers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -130,6 +155,7 @@ func setupRouter(cfg *config.Config, gameHandler *handler.GameHandler, wsHub *we
 	wsGroup := r.Group("/")
 	wsGroup.Use(middleware.WebSocketAuthMiddleware(cfg.JWTSecret))
 	wsGroup.GET("ws", wsHub.HandleWebSocket)
+	wsGroup.GET("ws/games/:gameId/spectate", gameHandler.SpectateGame)
 	logger.Info("Registered WebSocket route at /ws with WebSocket auth")
 
 	api := r.Group("/api/v1")
@@ -149,10 +175,14 @@ func setupRouter(cfg *config.Config, gameHandler *handler.GameHandler, wsHub *we
 	authGames.Use(middleware.AuthMiddleware(cfg.JWTSecret))
 	{
 		authGames.GET("/active", gameHandler.GetActiveGames)
+		authGames.GET("/spectatable", gameHandler.GetSpectatableGames)
 		authGames.GET("/:gameId", gameHandler.GetGame)
 		authGames.POST("/:gameId/join", gameHandler.JoinGame)
 		authGames.POST("/:gameId/mulligan", gameHandler.PerformMulligan)
 		authGames.POST("/:gameId/actions", gameHandler.PlayAction)
+		authGames.GET("/:gameId/legal-actions", gameHandler.GetLegalActions)
+		authGames.GET("/:gameId/replay", gameHandler.GetReplay)
+		authGames.GET("/:gameId/replay/stream", gameHandler.StreamReplay)
 		authGames.POST("/:gameId/surrender", gameHandler.SurrenderGame)
 	}
 
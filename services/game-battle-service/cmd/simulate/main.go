@@ -0,0 +1,146 @@
+// Command simulate plays bot-vs-bot games on the game engine alone, with no Postgres or Redis,
+// and reports win rates by deck, first-player advantage, game length and card play frequency.
+//
+//	go run ./cmd/simulate -pool ../../test_data/FULL_50_CARDS_DECK.json -games 1000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"ua/services/game-battle-service/internal/bot"
+)
+
+func main() {
+	poolFlag := flag.String("pool", "", "comma-separated card pool files in the test_data/*.json format (required)")
+	decksFlag := flag.String("decks", "", "comma-separated names of the decks to play (default: every loaded deck)")
+	build := flag.Bool("build", false, "also build one deck per color from the card pool")
+	list := flag.Bool("list", false, "list the available decks and exit")
+	games := flag.Int("games", 100, "games per matchup; seats alternate between games")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "number of games played at the same time")
+	strategy := flag.String("strategy", bot.StrategyGreedy, "bot strategy for both seats: random, greedy or lookahead")
+	seed := flag.Int64("seed", 1, "base random seed; game i uses seed+i")
+	maxActions := flag.Int("max-actions", 2000, "stop a game that has not finished after this many actions")
+	top := flag.Int("top", 20, "number of cards in the card play table, 0 for all")
+	jsonOutput := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *poolFlag == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pool, err := LoadCardPool(splitList(*poolFlag))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	decks := pool.Decks
+	if *build || len(decks) == 0 {
+		decks = append(decks, pool.BuildColorDecks()...)
+	}
+
+	if *list {
+		for _, deck := range decks {
+			fmt.Printf("%s (%d cards)\n", deck.Name, len(deck.Cards))
+		}
+		return
+	}
+
+	decks, err = selectDecks(decks, splitList(*decksFlag))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(decks) == 0 {
+		log.Fatal("no playable decks: provide 50-card decks or a pool large enough for -build")
+	}
+	if *games < 1 || *parallel < 1 {
+		log.Fatal("-games and -parallel must be at least 1")
+	}
+
+	jobs := buildJobs(len(decks), *games, *seed)
+	simulator := &Simulator{Decks: decks, Strategy: *strategy, MaxActions: *maxActions}
+
+	started := time.Now()
+	results := make([]GameResult, 0, len(jobs))
+	for result := range simulator.Run(jobs, *parallel) {
+		results = append(results, result)
+		if len(results)%100 == 0 {
+			fmt.Fprintf(os.Stderr, "%d/%d games\n", len(results), len(jobs))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "played %d games in %s\n", len(results), time.Since(started).Round(time.Millisecond))
+
+	report := NewReport(decks, *strategy, results)
+	if *jsonOutput {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout, *top)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// buildJobs schedules a round robin between all decks, or mirror matches when there is only one deck
+func buildJobs(deckCount, gamesPerMatchup int, seed int64) []Job {
+	type matchup struct{ a, b int }
+	matchups := []matchup{}
+	if deckCount == 1 {
+		matchups = append(matchups, matchup{0, 0})
+	}
+	for a := 0; a < deckCount; a++ {
+		for b := a + 1; b < deckCount; b++ {
+			matchups = append(matchups, matchup{a, b})
+		}
+	}
+
+	jobs := make([]Job, 0, len(matchups)*gamesPerMatchup)
+	for _, m := range matchups {
+		for i := 0; i < gamesPerMatchup; i++ {
+			job := Job{Index: len(jobs), First: m.a, Other: m.b}
+			if i%2 == 1 {
+				job.First, job.Other = m.b, m.a
+			}
+			job.Seed = seed + int64(job.Index)
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+func selectDecks(decks []Deck, names []string) ([]Deck, error) {
+	if len(names) == 0 {
+		return decks, nil
+	}
+
+	byName := make(map[string]Deck, len(decks))
+	for _, deck := range decks {
+		byName[deck.Name] = deck
+	}
+
+	selected := make([]Deck, 0, len(names))
+	for _, name := range names {
+		deck, exists := byName[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown deck %q (use -list to see the available decks)", name)
+		}
+		selected = append(selected, deck)
+	}
+	return selected, nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	deckSize      = 50
+	maxCopies     = 4
+	cardTypeAP    = "AP"
+	unknownColors = "COLORLESS"
+)
+
+// Deck is a named list of cards used by one seat in the simulation
+type Deck struct {
+	Name  string
+	Cards []models.Card
+}
+
+// CardPool holds every card and deck found in the loaded files
+type CardPool struct {
+	Cards []models.Card
+	Decks []Deck
+
+	byVariant map[string]models.Card
+	byNumber  map[string]models.Card
+}
+
+// deckEntry references a pool card by variant ID or card number, as in test_data deck lists
+type deckEntry struct {
+	CardVariantID string `json:"card_variant_id"`
+	CardNumber    string `json:"card_number"`
+	Quantity      int    `json:"quantity"`
+}
+
+// deckList is a deck defined by card references instead of full cards
+type deckList struct {
+	Name          string      `json:"name"`
+	Cards         []deckEntry `json:"cards"`
+	CardInstances []deckEntry `json:"card_instances"`
+}
+
+// LoadCardPool reads card pool files in the test_data/*.json format.
+// Every array of cards adds to the pool, an array of exactly 50 cards is also used as a deck,
+// and objects listing card_variant_id/quantity pairs become decks resolved against the pool.
+func LoadCardPool(paths []string) (*CardPool, error) {
+	pool := &CardPool{
+		byVariant: make(map[string]models.Card),
+		byNumber:  make(map[string]models.Card),
+	}
+
+	lists := []deckList{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		var top map[string]json.RawMessage
+		if err := json.Unmarshal(data, &top); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		prefix := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		keys := make([]string, 0, len(top))
+		for key := range top {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name := prefix + "/" + key
+			if cards, ok := parseCards(top[key]); ok {
+				pool.addCards(cards)
+				if len(cards) == deckSize {
+					pool.Decks = append(pool.Decks, Deck{Name: name, Cards: cards})
+				}
+				continue
+			}
+
+			lists = append(lists, parseDeckLists(name, top[key])...)
+		}
+	}
+
+	for _, list := range lists {
+		deck, err := pool.resolveDeckList(list)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping deck %s: %v\n", list.Name, err)
+			continue
+		}
+		pool.Decks = append(pool.Decks, deck)
+	}
+
+	if len(pool.Cards) == 0 {
+		return nil, fmt.Errorf("no cards found in %s", strings.Join(paths, ", "))
+	}
+	return pool, nil
+}
+
+// parseCards decodes a JSON array of full card objects
+func parseCards(raw json.RawMessage) ([]models.Card, bool) {
+	var probe []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil || len(probe) == 0 {
+		return nil, false
+	}
+	for _, item := range probe {
+		if _, exists := item["card_type"]; !exists {
+			return nil, false
+		}
+	}
+
+	var cards []models.Card
+	if err := json.Unmarshal(raw, &cards); err != nil {
+		return nil, false
+	}
+	return cards, true
+}
+
+// parseDeckLists decodes a deck list object, or an array of them
+func parseDeckLists(name string, raw json.RawMessage) []deckList {
+	var single deckList
+	if err := json.Unmarshal(raw, &single); err == nil && single.entries() != nil {
+		if single.Name == "" {
+			single.Name = name
+		} else {
+			single.Name = name + "/" + single.Name
+		}
+		return []deckList{single}
+	}
+
+	var many []deckList
+	if err := json.Unmarshal(raw, &many); err != nil {
+		return nil
+	}
+	lists := []deckList{}
+	for i, list := range many {
+		if list.entries() == nil {
+			continue
+		}
+		if list.Name == "" {
+			list.Name = fmt.Sprintf("%s/%d", name, i)
+		} else {
+			list.Name = name + "/" + list.Name
+		}
+		lists = append(lists, list)
+	}
+	return lists
+}
+
+func (l deckList) entries() []deckEntry {
+	if len(l.Cards) > 0 && (l.Cards[0].CardVariantID != "" || l.Cards[0].CardNumber != "") {
+		return l.Cards
+	}
+	if len(l.CardInstances) > 0 {
+		return l.CardInstances
+	}
+	return nil
+}
+
+func (p *CardPool) addCards(cards []models.Card) {
+	for _, card := range cards {
+		if card.CardVariantID != "" {
+			if _, exists := p.byVariant[card.CardVariantID]; exists {
+				continue
+			}
+			p.byVariant[card.CardVariantID] = card
+		}
+		if card.CardNumber != "" {
+			if _, exists := p.byNumber[card.CardNumber]; !exists {
+				p.byNumber[card.CardNumber] = card
+			}
+		}
+		p.Cards = append(p.Cards, card)
+	}
+}
+
+func (p *CardPool) resolveDeckList(list deckList) (Deck, error) {
+	deck := Deck{Name: list.Name}
+	for _, entry := range list.entries() {
+		card, exists := p.byVariant[entry.CardVariantID]
+		if !exists {
+			card, exists = p.byNumber[entry.CardNumber]
+		}
+		if !exists {
+			return Deck{}, fmt.Errorf("unknown card %s%s", entry.CardVariantID, entry.CardNumber)
+		}
+
+		quantity := entry.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		for i := 0; i < quantity; i++ {
+			deck.Cards = append(deck.Cards, card)
+		}
+	}
+
+	if len(deck.Cards) != deckSize {
+		return Deck{}, fmt.Errorf("deck has %d cards (required: %d)", len(deck.Cards), deckSize)
+	}
+	return deck, nil
+}
+
+// BuildColorDecks builds one 50-card deck per color from the pool.
+// Cards are taken in pool order, up to four copies each; colors without enough cards are skipped.
+func (p *CardPool) BuildColorDecks() []Deck {
+	byColor := map[string][]models.Card{}
+	seen := map[string]bool{}
+	for _, card := range p.Cards {
+		if card.CardType == cardTypeAP || seen[cardKey(card)] {
+			continue
+		}
+		seen[cardKey(card)] = true
+
+		color := card.Color
+		if color == "" {
+			color = unknownColors
+		}
+		byColor[color] = append(byColor[color], card)
+	}
+
+	colors := make([]string, 0, len(byColor))
+	for color := range byColor {
+		colors = append(colors, color)
+	}
+	sort.Strings(colors)
+
+	decks := []Deck{}
+	for _, color := range colors {
+		cards := byColor[color]
+		if len(cards)*maxCopies < deckSize {
+			fmt.Fprintf(os.Stderr, "skipping built %s deck: only %d different cards\n", color, len(cards))
+			continue
+		}
+
+		deck := Deck{Name: "built/" + color}
+		for copies := 0; copies < maxCopies && len(deck.Cards) < deckSize; copies++ {
+			for _, card := range cards {
+				if len(deck.Cards) == deckSize {
+					break
+				}
+				deck.Cards = append(deck.Cards, card)
+			}
+		}
+		decks = append(decks, deck)
+	}
+	return decks
+}
+
+// instantiate copies the deck with a fresh ID for every card, so copies of the same card can be told apart in play
+func (d Deck) instantiate() []models.Card {
+	cards := make([]models.Card, len(d.Cards))
+	for i, card := range d.Cards {
+		card.ID = uuid.New()
+		cards[i] = card
+	}
+	return cards
+}
+
+// cardKey identifies a card across decks for play statistics
+func cardKey(card models.Card) string {
+	if card.CardNumber != "" {
+		return card.CardNumber + " " + card.Name
+	}
+	return card.Name
+}
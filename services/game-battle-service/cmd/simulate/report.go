@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Report aggregates the results of every simulated game
+type Report struct {
+	Strategy     string         `json:"strategy"`
+	Games        int            `json:"games"`
+	Finished     int            `json:"finished"`
+	Unfinished   map[string]int `json:"unfinished,omitempty"` // reason -> games
+	FirstPlayer  RateStats      `json:"first_player"`
+	AverageTurns float64        `json:"average_turns"`
+	Decks        []DeckStats    `json:"decks"`
+	Matchups     []MatchupStats `json:"matchups"`
+	CardPlays    []CardStats    `json:"card_plays"`
+}
+
+// RateStats is a win count over finished games
+type RateStats struct {
+	Games   int     `json:"games"`
+	Wins    int     `json:"wins"`
+	WinRate float64 `json:"win_rate"`
+}
+
+// DeckStats is the record of one deck against the other decks; its finished mirror matches are only counted in Mirrors
+type DeckStats struct {
+	Name string `json:"name"`
+	RateStats
+	Mirrors    int `json:"mirrors"`
+	Unfinished int `json:"unfinished"`
+}
+
+// MatchupStats is the record of the first deck against the second
+type MatchupStats struct {
+	Deck     string `json:"deck"`
+	Opponent string `json:"opponent"`
+	RateStats
+}
+
+// CardStats counts how often a card was played
+type CardStats struct {
+	Card    string  `json:"card"`
+	Plays   int     `json:"plays"`
+	PerGame float64 `json:"per_game"`
+}
+
+// NewReport builds the report from all game results
+func NewReport(decks []Deck, strategy string, results []GameResult) *Report {
+	report := &Report{
+		Strategy:   strategy,
+		Games:      len(results),
+		Unfinished: map[string]int{},
+		Decks:      make([]DeckStats, len(decks)),
+	}
+	for i, deck := range decks {
+		report.Decks[i].Name = deck.Name
+	}
+
+	type pair struct{ deck, opponent int }
+	matchups := map[pair]*MatchupStats{}
+	plays := map[string]int{}
+	turns := 0
+
+	for _, result := range results {
+		for card, count := range result.Played {
+			plays[card] += count
+		}
+
+		seats := [2]int{result.First, result.Other}
+		mirror := result.First == result.Other
+
+		if !result.Finished {
+			report.Unfinished[result.Reason]++
+			report.Decks[result.First].Unfinished++
+			if !mirror {
+				report.Decks[result.Other].Unfinished++
+			}
+			continue
+		}
+
+		report.Finished++
+		turns += result.Turns
+
+		report.FirstPlayer.Games++
+		if result.Winner == 0 {
+			report.FirstPlayer.Wins++
+		}
+
+		// A mirror match always has the deck on both sides, so it says nothing about the deck's win rate
+		if mirror {
+			report.Decks[result.First].Mirrors++
+			continue
+		}
+
+		for seat, deck := range seats {
+			won := result.Winner == seat
+			report.Decks[deck].Games++
+			if won {
+				report.Decks[deck].Wins++
+			}
+
+			// Each pairing is recorded once, from the side of the deck listed first
+			opponent := seats[1-seat]
+			if deck > opponent {
+				continue
+			}
+			key := pair{deck, opponent}
+			matchup, exists := matchups[key]
+			if !exists {
+				matchup = &MatchupStats{Deck: decks[deck].Name, Opponent: decks[opponent].Name}
+				matchups[key] = matchup
+			}
+			matchup.Games++
+			if won {
+				matchup.Wins++
+			}
+		}
+	}
+
+	if report.Finished > 0 {
+		report.AverageTurns = float64(turns) / float64(report.Finished)
+	}
+	report.FirstPlayer.finish()
+	for i := range report.Decks {
+		report.Decks[i].finish()
+	}
+
+	for _, matchup := range matchups {
+		matchup.finish()
+		report.Matchups = append(report.Matchups, *matchup)
+	}
+	sort.Slice(report.Matchups, func(i, j int) bool {
+		if report.Matchups[i].Deck != report.Matchups[j].Deck {
+			return report.Matchups[i].Deck < report.Matchups[j].Deck
+		}
+		return report.Matchups[i].Opponent < report.Matchups[j].Opponent
+	})
+
+	for card, count := range plays {
+		stats := CardStats{Card: card, Plays: count}
+		if report.Games > 0 {
+			stats.PerGame = float64(count) / float64(report.Games)
+		}
+		report.CardPlays = append(report.CardPlays, stats)
+	}
+	sort.Slice(report.CardPlays, func(i, j int) bool {
+		if report.CardPlays[i].Plays != report.CardPlays[j].Plays {
+			return report.CardPlays[i].Plays > report.CardPlays[j].Plays
+		}
+		return report.CardPlays[i].Card < report.CardPlays[j].Card
+	})
+
+	return report
+}
+
+func (r *RateStats) finish() {
+	if r.Games > 0 {
+		r.WinRate = float64(r.Wins) / float64(r.Games)
+	}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report as plain text tables. topCards limits the card play table, 0 shows every card.
+func (r *Report) WriteText(w io.Writer, topCards int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Games\t%d (finished %d)\n", r.Games, r.Finished)
+	fmt.Fprintf(tw, "Strategy\t%s\n", r.Strategy)
+	fmt.Fprintf(tw, "Average turns\t%.1f\n", r.AverageTurns)
+	fmt.Fprintf(tw, "First player win rate\t%.1f%% (%d/%d)\n", r.FirstPlayer.WinRate*100, r.FirstPlayer.Wins, r.FirstPlayer.Games)
+	for reason, count := range r.Unfinished {
+		fmt.Fprintf(tw, "Unfinished: %s\t%d\n", reason, count)
+	}
+
+	fmt.Fprintln(tw, "\nDECK\tGAMES\tWINS\tWIN RATE\tMIRRORS\tUNFINISHED")
+	for _, deck := range r.Decks {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%d\t%d\n", deck.Name, deck.Games, deck.Wins, deck.WinRate*100, deck.Mirrors, deck.Unfinished)
+	}
+
+	if len(r.Matchups) > 0 {
+		fmt.Fprintln(tw, "\nDECK\tOPPONENT\tGAMES\tWIN RATE")
+		for _, matchup := range r.Matchups {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%.1f%%\n", matchup.Deck, matchup.Opponent, matchup.Games, matchup.WinRate*100)
+		}
+	}
+
+	fmt.Fprintln(tw, "\nCARD\tPLAYS\tPER GAME")
+	for i, card := range r.CardPlays {
+		if topCards > 0 && i == topCards {
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\n", card.Card, card.Plays, card.PerGame)
+	}
+
+	return tw.Flush()
+}
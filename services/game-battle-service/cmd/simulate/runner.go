@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"ua/services/game-battle-service/internal/bot"
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// Job is one game between two decks; First is the index of the deck that takes the first turn
+type Job struct {
+	Index int
+	First int
+	Other int
+	Seed  int64
+}
+
+// GameResult is the outcome of one simulated game
+type GameResult struct {
+	Job
+	Winner   int    // seat of the winner: 0 took the first turn, 1 the second, -1 when the game did not finish
+	Reason   string // win reason, or why the game was stopped
+	Turns    int
+	Actions  int
+	Played   map[string]int // card key -> times played
+	Finished bool
+}
+
+// Simulator plays engine-only games between bots
+type Simulator struct {
+	Decks      []Deck
+	Strategy   string
+	MaxActions int
+}
+
+// Run plays every job on the given number of workers and streams the results
+func (s *Simulator) Run(jobs []Job, workers int) <-chan GameResult {
+	queue := make(chan Job)
+	results := make(chan GameResult)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				results <- s.play(job)
+			}
+		}()
+	}
+
+	go func() {
+		for _, job := range jobs {
+			queue <- job
+		}
+		close(queue)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// play runs a single game. Each game gets its own engine and bots, seeded from the job.
+func (s *Simulator) play(job Job) GameResult {
+	ctx := context.Background()
+	result := GameResult{Job: job, Winner: -1, Played: map[string]int{}}
+
+	rng := rand.New(rand.NewPCG(uint64(job.Seed), uint64(job.Index)))
+	gameEngine := engine.NewGameEngine(rng, engine.SystemClock{})
+
+	playerIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	bots := map[uuid.UUID]*bot.Bot{}
+	seatOf := map[uuid.UUID]int{}
+	for i, playerID := range playerIDs {
+		strategy, err := bot.NewStrategy(s.Strategy, gameEngine, rand.New(rand.NewPCG(uint64(job.Seed), uint64(i))))
+		if err != nil {
+			result.Reason = err.Error()
+			return result
+		}
+		bots[playerID] = bot.NewBot(playerID, strategy, gameEngine)
+		seatOf[playerID] = i
+	}
+
+	gameID := uuid.New()
+	seed := job.Seed
+	gameState, err := gameEngine.InitializeGame(ctx, &engine.InitGameRequest{
+		GameID:  gameID,
+		Player1: &engine.PlayerSetup{UserID: playerIDs[0], Deck: s.Decks[job.First].instantiate()},
+		Player2: &engine.PlayerSetup{UserID: playerIDs[1], Deck: s.Decks[job.Other].instantiate()},
+		Seed:    &seed,
+	})
+	if err != nil {
+		result.Reason = fmt.Sprintf("failed to initialize game: %v", err)
+		return result
+	}
+	defer gameEngine.UnloadGame(ctx, gameID)
+
+	for _, playerID := range playerIDs {
+		mulligan := bots[playerID].Mulligan(gameState.Players[playerID].Hand)
+		// The engine hands out copies, so the state is replaced after every change
+		gameState, err = gameEngine.PerformMulligan(ctx, &engine.MulliganRequest{GameID: gameID, PlayerID: playerID, Mulligan: mulligan})
+		if err != nil {
+			result.Reason = fmt.Sprintf("failed to mulligan: %v", err)
+			return result
+		}
+	}
+
+	for result.Actions < s.MaxActions {
+		winCondition, _ := gameEngine.CheckWinCondition(ctx, gameState)
+		if winCondition.HasWinner && winCondition.Winner != nil {
+			result.Winner = seatOf[*winCondition.Winner]
+			result.Reason = winCondition.Reason
+			result.Finished = true
+			result.Turns = gameState.Turn
+			return result
+		}
+
+		acted := false
+		for _, playerID := range playerIDs {
+			action, ok := bots[playerID].NextAction(gameState)
+			if !ok {
+				continue
+			}
+
+			if action.ActionType == models.ActionTypePlayCard && action.ActionData.CardID != nil {
+				if card := findInHand(gameState.Players[playerID], *action.ActionData.CardID); card != nil {
+					result.Played[cardKey(*card)]++
+				}
+			}
+
+			actionData, _ := json.Marshal(action.ActionData)
+			actionResult, err := gameEngine.ProcessAction(ctx, gameID, &models.GameAction{
+				ID:         uuid.New(),
+				GameID:     gameID,
+				PlayerID:   playerID,
+				ActionType: action.ActionType,
+				ActionData: actionData,
+			})
+			if err != nil || !actionResult.Success {
+				result.Reason = fmt.Sprintf("bot action %s failed", action.ActionType)
+				result.Turns = gameState.Turn
+				return result
+			}
+
+			gameState = actionResult.GameState
+			result.Actions++
+			acted = true
+			break
+		}
+
+		if !acted {
+			result.Reason = "no player can act"
+			result.Turns = gameState.Turn
+			return result
+		}
+	}
+
+	result.Reason = "action limit reached"
+	result.Turns = gameState.Turn
+	return result
+}
+
+func findInHand(player *models.Player, cardID uuid.UUID) *models.Card {
+	for i := range player.Hand {
+		if player.Hand[i].ID == cardID {
+			return &player.Hand[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"math/rand/v2"
+
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// 內建機器人的玩家ID，對應 database/init.sql 中的機器人帳號
+// 以機器人的玩家ID作為 player1_id 或 player2_id 創建遊戲，即可讓機器人坐在該座位
+var (
+	RandomBotID    = uuid.MustParse("00000000-0000-4000-8000-00000000b001")
+	GreedyBotID    = uuid.MustParse("00000000-0000-4000-8000-00000000b002")
+	LookaheadBotID = uuid.MustParse("00000000-0000-4000-8000-00000000b003")
+)
+
+// lookaheadWidth 前瞻策略向前模擬的動作數量
+const lookaheadWidth = 5
+
+// minMulliganCharacters 初始手牌的角色卡少於此數量時調度
+const minMulliganCharacters = 2
+
+// Bot 由策略控制的玩家
+type Bot struct {
+	PlayerID uuid.UUID
+	Strategy Strategy
+	engine   engine.GameEngine
+}
+
+// NewBot 創建機器人
+func NewBot(playerID uuid.UUID, strategy Strategy, gameEngine engine.GameEngine) *Bot {
+	return &Bot{
+		PlayerID: playerID,
+		Strategy: strategy,
+		engine:   gameEngine,
+	}
+}
+
+// NextAction 返回機器人接下來要執行的動作
+// 雙方完成調度前或機器人目前不需要行動時返回false
+func (b *Bot) NextAction(gameState *models.GameState) (engine.LegalAction, bool) {
+	if !gameState.LifeAreaSetup || gameState.Players[b.PlayerID] == nil {
+		return engine.LegalAction{}, false
+	}
+
+	actions := playableActions(b.engine.LegalActions(gameState, b.PlayerID))
+	if len(actions) == 0 {
+		return engine.LegalAction{}, false
+	}
+
+	return b.Strategy.Choose(gameState, b.PlayerID, actions), true
+}
+
+// Mulligan 決定是否調度初始手牌
+// 角色卡太少時調度
+func (b *Bot) Mulligan(hand []models.Card) bool {
+	characters := 0
+	for _, card := range hand {
+		if card.CardType == models.CardTypeCharacter {
+			characters++
+		}
+	}
+	return characters < minMulliganCharacters
+}
+
+// Roster 內建機器人的名冊
+type Roster struct {
+	bots map[uuid.UUID]*Bot
+}
+
+// NewRoster 創建包含所有內建機器人的名冊
+func NewRoster(gameEngine engine.GameEngine, rng *rand.Rand) *Roster {
+	return &Roster{
+		bots: map[uuid.UUID]*Bot{
+			RandomBotID:    NewBot(RandomBotID, NewRandomStrategy(rng), gameEngine),
+			GreedyBotID:    NewBot(GreedyBotID, NewGreedyStrategy(gameEngine), gameEngine),
+			LookaheadBotID: NewBot(LookaheadBotID, NewLookaheadStrategy(gameEngine, lookaheadWidth), gameEngine),
+		},
+	}
+}
+
+// Get 獲取玩家ID對應的機器人，不是機器人時返回false
+func (r *Roster) Get(playerID uuid.UUID) (*Bot, bool) {
+	if r == nil {
+		return nil, false
+	}
+	b, exists := r.bots[playerID]
+	return b, exists
+}
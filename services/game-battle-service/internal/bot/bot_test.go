@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// maxTestActions 每場測試遊戲最多執行的動作數量
+const maxTestActions = 12
+
+// newTestDeck 建立測試用的50張角色卡卡組
+func newTestDeck() []models.Card {
+	deck := make([]models.Card, 0, 50)
+	for i := 0; i < 50; i++ {
+		bp := 3000 + (i%5)*1000
+		deck = append(deck, models.Card{
+			ID:            uuid.New(),
+			Name:          "Test Character",
+			CardType:      models.CardTypeCharacter,
+			Color:         "RED",
+			BP:            &bp,
+			APCost:        1,
+			EnergyCost:    json.RawMessage(`{}`),
+			EnergyProduce: json.RawMessage(`{"red":1}`),
+		})
+	}
+	return deck
+}
+
+// playBotGame 由兩個機器人在引擎中進行一場遊戲，直到分出勝負或達到動作上限
+func playBotGame(t *testing.T, gameEngine engine.GameEngine, bots []*Bot) {
+	ctx := context.Background()
+	gameID := uuid.New()
+
+	gameState, err := gameEngine.InitializeGame(ctx, &engine.InitGameRequest{
+		GameID:  gameID,
+		Player1: &engine.PlayerSetup{UserID: bots[0].PlayerID, Deck: newTestDeck()},
+		Player2: &engine.PlayerSetup{UserID: bots[1].PlayerID, Deck: newTestDeck()},
+	})
+	if err != nil {
+		t.Errorf("failed to initialize game: %v", err)
+		return
+	}
+
+	for _, b := range bots {
+		if _, err := gameEngine.PerformMulligan(ctx, &engine.MulliganRequest{
+			GameID:   gameID,
+			PlayerID: b.PlayerID,
+			Mulligan: b.Mulligan(gameState.Players[b.PlayerID].Hand),
+		}); err != nil {
+			t.Errorf("failed to perform mulligan: %v", err)
+			return
+		}
+	}
+
+	for i := 0; i < maxTestActions; i++ {
+		gameState, err := gameEngine.GetGameState(ctx, gameID)
+		if err != nil {
+			t.Errorf("failed to get game state: %v", err)
+			return
+		}
+		if winCondition, err := gameEngine.CheckWinCondition(ctx, gameState); err != nil || winCondition.HasWinner {
+			return
+		}
+
+		acted := false
+		for _, b := range bots {
+			action, ok := b.NextAction(gameState)
+			if !ok {
+				continue
+			}
+
+			actionData, err := json.Marshal(action.ActionData)
+			if err != nil {
+				t.Errorf("failed to serialize bot action: %v", err)
+				return
+			}
+			result, err := gameEngine.ProcessAction(ctx, gameID, &models.GameAction{
+				ID:         uuid.New(),
+				GameID:     gameID,
+				PlayerID:   b.PlayerID,
+				ActionType: action.ActionType,
+				ActionData: actionData,
+			})
+			if err != nil {
+				t.Errorf("failed to process bot action: %v", err)
+				return
+			}
+			if !result.Success {
+				t.Errorf("bot chose an illegal action %s: %s", action.ActionType, result.Error)
+				return
+			}
+			acted = true
+			break
+		}
+
+		if !acted {
+			t.Error("no bot could act")
+			return
+		}
+	}
+}
+
+// TestConcurrentBotGames 同時進行兩場共用同一個隨機策略的機器人遊戲
+// 與名冊相同，策略同時為多場遊戲選擇動作；以 go test -race 執行，確認策略可以同時使用
+func TestConcurrentBotGames(t *testing.T) {
+	clock := &engine.ManualClock{}
+	clock.Set(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	gameEngine := engine.NewGameEngine(rand.New(rand.NewPCG(1, 2)), clock)
+	strategy := NewRandomStrategy(rand.New(rand.NewPCG(3, 4)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		bots := []*Bot{
+			NewBot(uuid.New(), strategy, gameEngine),
+			NewBot(uuid.New(), strategy, gameEngine),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			playBotGame(t, gameEngine, bots)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// 局面評估的權重
+const (
+	winScore        = 1000000 // 獲勝（失敗時為負值）
+	lifeCardScore   = 100     // 每張生命區卡片
+	handCardScore   = 5       // 每張手牌
+	frontLineScore  = 20      // 每個前線角色
+	energyLineScore = 15      // 每個能源線角色
+	bpScoreUnit     = 500     // 角色每500BP加1分
+	energyScore     = 3       // 每點可用能源
+	apScore         = 2       // 每點剩餘AP
+)
+
+// evaluate 從玩家的角度評估局面
+// 分數越高對玩家越有利，以雙方的生命區、手牌、場上角色與資源的差距計算
+func evaluate(gameEngine engine.GameEngine, gameState *models.GameState, playerID uuid.UUID) int {
+	if winCondition, err := gameEngine.CheckWinCondition(context.Background(), gameState); err == nil && winCondition.HasWinner && winCondition.Winner != nil {
+		if *winCondition.Winner == playerID {
+			return winScore
+		}
+		return -winScore
+	}
+
+	score := 0
+	for id, player := range gameState.Players {
+		if id == playerID {
+			score += playerScore(gameState, player)
+		} else {
+			score -= playerScore(gameState, player)
+		}
+	}
+	return score
+}
+
+// playerScore 計算一名玩家的局面分數
+func playerScore(gameState *models.GameState, player *models.Player) int {
+	score := len(player.Board.LifeArea)*lifeCardScore + len(player.Hand)*handCardScore + player.AP*apScore
+
+	for i := range player.Board.FrontLine {
+		score += frontLineScore + engine.EffectiveBP(&player.Board.FrontLine[i], gameState)/bpScoreUnit
+	}
+	for i := range player.Board.EnergyLine {
+		score += energyLineScore + engine.EffectiveBP(&player.Board.EnergyLine[i], gameState)/bpScoreUnit
+	}
+	for _, amount := range player.AvailableEnergy() {
+		score += amount * energyScore
+	}
+
+	return score
+}
+
+// actingPlayer 返回目前需要行動的玩家
+// 有待決選擇時為做出選擇的玩家，攻擊等待防禦宣告時為防禦方，其他時候為回合玩家
+func actingPlayer(gameState *models.GameState) uuid.UUID {
+	if len(gameState.PendingDecisions) > 0 {
+		return gameState.PendingDecisions[0].PlayerID
+	}
+	if gameState.PendingAttack != nil {
+		return gameState.PendingAttack.DefendingPlayer
+	}
+	return gameState.ActivePlayer
+}
@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// 策略名稱
+const (
+	StrategyRandom    = "random"
+	StrategyGreedy    = "greedy"
+	StrategyLookahead = "lookahead"
+)
+
+// maxSettleSteps 評估動作時最多模擬的對手回應次數
+const maxSettleSteps = 8
+
+// Strategy 機器人選擇動作的策略
+// Choose 從合法動作中選擇一個動作，actions 至少包含一個動作
+type Strategy interface {
+	Name() string
+	Choose(gameState *models.GameState, playerID uuid.UUID, actions []engine.LegalAction) engine.LegalAction
+}
+
+// NewStrategy 依名稱創建策略
+func NewStrategy(name string, gameEngine engine.GameEngine, rng *rand.Rand) (Strategy, error) {
+	switch name {
+	case StrategyRandom:
+		return NewRandomStrategy(rng), nil
+	case StrategyGreedy:
+		return NewGreedyStrategy(gameEngine), nil
+	case StrategyLookahead:
+		return NewLookaheadStrategy(gameEngine, lookaheadWidth), nil
+	default:
+		return nil, fmt.Errorf("unknown bot strategy: %s", name)
+	}
+}
+
+// RandomStrategy 隨機選擇合法動作的策略
+// 名冊中的同一個策略會同時為多場遊戲選擇動作，因此亂數產生器以鎖保護
+type RandomStrategy struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewRandomStrategy 創建隨機策略
+func NewRandomStrategy(rng *rand.Rand) *RandomStrategy {
+	return &RandomStrategy{rng: rng}
+}
+
+// Name 返回策略名稱
+func (s *RandomStrategy) Name() string {
+	return StrategyRandom
+}
+
+// Choose 隨機選擇一個動作
+func (s *RandomStrategy) Choose(gameState *models.GameState, playerID uuid.UUID, actions []engine.LegalAction) engine.LegalAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return actions[s.rng.IntN(len(actions))]
+}
+
+// GreedyStrategy 選擇執行後局面評估最高的動作的策略
+// 評估前先模擬對手對動作的回應（防禦宣告、選擇），對手選擇對機器人最不利的回應
+type GreedyStrategy struct {
+	engine engine.GameEngine
+}
+
+// NewGreedyStrategy 創建貪婪策略
+func NewGreedyStrategy(gameEngine engine.GameEngine) *GreedyStrategy {
+	return &GreedyStrategy{engine: gameEngine}
+}
+
+// Name 返回策略名稱
+func (s *GreedyStrategy) Name() string {
+	return StrategyGreedy
+}
+
+// Choose 選擇局面評估最高的動作
+func (s *GreedyStrategy) Choose(gameState *models.GameState, playerID uuid.UUID, actions []engine.LegalAction) engine.LegalAction {
+	scores := make([]int, len(actions))
+	for i, action := range actions {
+		scores[i], _ = scoreAction(s.engine, gameState, playerID, action)
+	}
+	return chooseBest(actions, scores)
+}
+
+// LookaheadStrategy 向前多看一步的策略
+// 對評估最高的數個動作，再模擬機器人接下來的最佳動作，以兩步後的局面評估選擇動作
+type LookaheadStrategy struct {
+	engine engine.GameEngine
+	width  int // 向前模擬的動作數量
+}
+
+// NewLookaheadStrategy 創建前瞻策略
+func NewLookaheadStrategy(gameEngine engine.GameEngine, width int) *LookaheadStrategy {
+	if width < 1 {
+		width = 1
+	}
+	return &LookaheadStrategy{engine: gameEngine, width: width}
+}
+
+// Name 返回策略名稱
+func (s *LookaheadStrategy) Name() string {
+	return StrategyLookahead
+}
+
+// Choose 選擇兩步後局面評估最高的動作
+// 結束階段的動作不向前模擬，以執行後的局面評估作為其他動作比較的基準
+func (s *LookaheadStrategy) Choose(gameState *models.GameState, playerID uuid.UUID, actions []engine.LegalAction) engine.LegalAction {
+	scores := make([]int, len(actions))
+	states := make([]*models.GameState, len(actions))
+	for i, action := range actions {
+		scores[i], states[i] = scoreAction(s.engine, gameState, playerID, action)
+	}
+
+	order := make([]int, len(actions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	if len(order) > s.width {
+		order = order[:s.width]
+	}
+
+	pass := passAction(actions)
+	lookahead := make([]int, len(actions))
+	copy(lookahead, scores)
+	for _, i := range order {
+		if i == pass || states[i] == nil {
+			continue
+		}
+		if best, ok := s.bestFollowUp(states[i], playerID); ok && best > lookahead[i] {
+			lookahead[i] = best
+		}
+	}
+
+	return chooseBest(actions, lookahead)
+}
+
+// bestFollowUp 返回機器人在模擬局面中下一個動作的最高評估
+// 輪到對手行動或遊戲已經結束時不再模擬
+func (s *LookaheadStrategy) bestFollowUp(gameState *models.GameState, playerID uuid.UUID) (int, bool) {
+	if actingPlayer(gameState) != playerID {
+		return 0, false
+	}
+	if evaluate(s.engine, gameState, playerID) == winScore {
+		return 0, false
+	}
+
+	actions := playableActions(s.engine.LegalActions(gameState, playerID))
+	if len(actions) == 0 {
+		return 0, false
+	}
+
+	best := -winScore
+	for _, action := range actions {
+		if score, _ := scoreAction(s.engine, gameState, playerID, action); score > best {
+			best = score
+		}
+	}
+	return best, true
+}
+
+// scoreAction 評估動作執行並由對手回應後的局面
+// 返回評估分數與模擬後的局面，動作無法執行時返回最低分
+func scoreAction(gameEngine engine.GameEngine, gameState *models.GameState, playerID uuid.UUID, action engine.LegalAction) (int, *models.GameState) {
+	simulated, err := gameEngine.SimulateAction(gameState, playerID, action)
+	if err != nil {
+		return -winScore, nil
+	}
+
+	simulated = settle(gameEngine, simulated, playerID)
+	return evaluate(gameEngine, simulated, playerID), simulated
+}
+
+// settle 模擬對手對動作的回應
+// 攻擊等待對手防禦宣告或對手有待決選擇時，對手選擇對機器人最不利的回應，直到輪到機器人或進入對手的回合
+func settle(gameEngine engine.GameEngine, gameState *models.GameState, playerID uuid.UUID) *models.GameState {
+	for step := 0; step < maxSettleSteps; step++ {
+		if len(gameState.PendingDecisions) == 0 && gameState.PendingAttack == nil {
+			return gameState
+		}
+
+		opponentID := actingPlayer(gameState)
+		if opponentID == playerID {
+			return gameState
+		}
+
+		var worst *models.GameState
+		worstScore := 0
+		for _, response := range playableActions(gameEngine.LegalActions(gameState, opponentID)) {
+			simulated, err := gameEngine.SimulateAction(gameState, opponentID, response)
+			if err != nil {
+				continue
+			}
+			if score := evaluate(gameEngine, simulated, playerID); worst == nil || score < worstScore {
+				worst, worstScore = simulated, score
+			}
+		}
+		if worst == nil {
+			return gameState
+		}
+		gameState = worst
+	}
+	return gameState
+}
+
+// chooseBest 選擇分數最高的動作，同分時選擇較前面的動作
+// 以結束階段（或結束回合）的分數為基準，其他動作必須高於基準才會被選擇，避免重複執行沒有進展的動作
+func chooseBest(actions []engine.LegalAction, scores []int) engine.LegalAction {
+	best := 0
+	for i := range actions {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	if pass := passAction(actions); pass >= 0 && scores[best] <= scores[pass] {
+		return actions[pass]
+	}
+	return actions[best]
+}
+
+// passAction 返回結束階段動作的位置，沒有結束階段時為結束回合，都沒有時返回-1
+func passAction(actions []engine.LegalAction) int {
+	endTurn := -1
+	for i, action := range actions {
+		switch action.ActionType {
+		case models.ActionTypeEndPhase:
+			return i
+		case models.ActionTypeEndTurn:
+			if endTurn < 0 {
+				endTurn = i
+			}
+		}
+	}
+	return endTurn
+}
+
+// playableActions 過濾機器人不會執行的動作
+// 機器人不投降；回合開始時引擎已自動抽牌，因此不執行起始階段的抽牌動作
+func playableActions(actions []engine.LegalAction) []engine.LegalAction {
+	playable := make([]engine.LegalAction, 0, len(actions))
+	for _, action := range actions {
+		if action.ActionType == models.ActionTypeSurrender || action.ActionType == models.ActionTypeDrawCard {
+			continue
+		}
+		playable = append(playable, action)
+	}
+	return playable
+}
@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 提供引擎使用的時間來源
+// 模擬與重播時可替換為固定或手動推進的時鐘，讓事件時間戳與期限可以重現
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock 使用系統時間的時鐘
+type SystemClock struct{}
+
+// Now 返回目前的系統時間
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock 由呼叫者設定時間的時鐘
+// 重播時設為每個動作記錄的時間，讓重建的狀態中的時間戳與期限和原本相同
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Set 設定目前時間
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Now 返回最後設定的時間
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
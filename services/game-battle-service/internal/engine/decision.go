@@ -0,0 +1,331 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// decisionTimeout 玩家做出選擇的預設期限
+const decisionTimeout = 60 * time.Second
+
+// handLimit 結束階段時手牌的上限
+const handLimit = 8
+
+// DecisionResolver 處理玩家對待決選擇的回應
+// choices 已經過驗證，只包含選擇中存在的選項ID
+type DecisionResolver func(e *gameEngine, gameState *models.GameState, decision *models.PendingDecision, choices []string, result *ActionResult) error
+
+// registerDecisionResolvers 註冊所有待決選擇的處理器
+// 將各種選擇類型映射到對應的處理函數
+func (e *gameEngine) registerDecisionResolvers() {
+	e.decisionResolvers[models.DecisionTypeLifeTrigger] = resolveLifeTriggerDecision
+	e.decisionResolvers[models.DecisionTypeDiscard] = resolveDiscardDecision
+	e.decisionResolvers[models.DecisionTypeTriggerOrder] = resolveTriggerOrderDecision
+}
+
+// requestDecision 建立待決選擇
+// 設置選擇ID與期限後加入佇列，並觸發 DECISION_REQUIRED 事件
+func (e *gameEngine) requestDecision(gameState *models.GameState, decision models.PendingDecision, result *ActionResult) {
+	decision.ID = newID(gameState)
+	decision.Deadline = e.clock.Now().Add(decisionTimeout)
+	gameState.PendingDecisions = append(gameState.PendingDecisions, decision)
+
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:   "DECISION_REQUIRED",
+		Source: decision.SourceCardID,
+		Target: &decision.PlayerID,
+		Data: map[string]interface{}{
+			"decision_id":   decision.ID,
+			"decision_type": decision.Type,
+			"prompt":        decision.Prompt,
+		},
+		Timestamp: e.clock.Now(),
+	})
+
+	logger.Debug("Decision requested",
+		zap.String("player", decision.PlayerID.String()),
+		zap.String("decision_type", decision.Type),
+		zap.Int("queued_decisions", len(gameState.PendingDecisions)))
+}
+
+// validateDuringPendingDecision 驗證待決選擇期間的動作
+// 只有需要做出選擇的玩家可以回應當前選擇，其他動作（投降除外）都必須等待
+func (e *gameEngine) validateDuringPendingDecision(gameState *models.GameState, action *models.GameAction) error {
+	if gameState.Players[action.PlayerID] == nil {
+		return fmt.Errorf("player not found")
+	}
+
+	switch action.ActionType {
+	case models.ActionTypeResolveDecision:
+		if action.PlayerID != gameState.PendingDecisions[0].PlayerID {
+			return fmt.Errorf("waiting for opponent decision")
+		}
+		return nil
+	case models.ActionTypeSurrender:
+		return nil
+	default:
+		return fmt.Errorf("pending decision must be resolved first")
+	}
+}
+
+// processResolveDecision 處理玩家對當前待決選擇的回應
+// 驗證選擇ID與選項後移出佇列，並交由對應類型的處理器繼續處理
+func (e *gameEngine) processResolveDecision(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
+	actionData, err := parseActionData(action.ActionData)
+	if err != nil {
+		result.Success = false
+		result.Error = "invalid action data"
+		return
+	}
+
+	decision := gameState.PendingDecisions[0]
+	if actionData.DecisionID == nil || *actionData.DecisionID != decision.ID {
+		result.Success = false
+		result.Error = "decision_id does not match the pending decision"
+		return
+	}
+
+	if err := validateDecisionChoices(&decision, actionData.Choices); err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		return
+	}
+
+	e.resolveDecision(gameState, &decision, actionData.Choices, result)
+
+	// 結束回合途中需要的選擇都解決後，繼續結束回合
+	if result.Success && gameState.EndingTurn && len(gameState.PendingDecisions) == 0 {
+		e.finishTurn(gameState, result)
+	}
+}
+
+// resolveDecision 解決當前待決選擇
+// 將選擇移出佇列後呼叫對應類型的處理器，處理器需在修改遊戲狀態前完成驗證
+func (e *gameEngine) resolveDecision(gameState *models.GameState, decision *models.PendingDecision, choices []string, result *ActionResult) {
+	resolver, exists := e.decisionResolvers[decision.Type]
+	if !exists {
+		result.Success = false
+		result.Error = "unknown decision type: " + decision.Type
+		return
+	}
+
+	gameState.PendingDecisions = gameState.PendingDecisions[1:]
+
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:      "DECISION_RESOLVED",
+		Source:    decision.SourceCardID,
+		Target:    &decision.PlayerID,
+		Data:      map[string]interface{}{"decision_id": decision.ID, "decision_type": decision.Type, "choices": choices},
+		Timestamp: e.clock.Now(),
+	})
+
+	if err := resolver(e, gameState, decision, choices, result); err != nil {
+		logger.Error("Failed to resolve decision",
+			zap.Error(err),
+			zap.String("decision_type", decision.Type))
+		// 處理失敗時保留選擇，讓玩家可以重新回應
+		gameState.PendingDecisions = append([]models.PendingDecision{*decision}, gameState.PendingDecisions...)
+		result.Success = false
+		result.Error = err.Error()
+	}
+}
+
+// validateDecisionChoices 驗證玩家的選擇
+// 檢查選擇數量是否在範圍內、選項是否存在且沒有重複
+func validateDecisionChoices(decision *models.PendingDecision, choices []string) error {
+	if len(choices) < decision.MinChoices || len(choices) > decision.MaxChoices {
+		if decision.MinChoices == decision.MaxChoices {
+			return fmt.Errorf("must choose exactly %d option(s)", decision.MinChoices)
+		}
+		return fmt.Errorf("must choose between %d and %d option(s)", decision.MinChoices, decision.MaxChoices)
+	}
+
+	seen := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		if seen[choice] {
+			return fmt.Errorf("duplicate choice: %s", choice)
+		}
+		seen[choice] = true
+
+		if findDecisionOption(decision, choice) == nil {
+			return fmt.Errorf("invalid choice: %s", choice)
+		}
+	}
+
+	return nil
+}
+
+// findDecisionOption 在選擇中尋找指定的選項
+func findDecisionOption(decision *models.PendingDecision, optionID string) *models.DecisionOption {
+	for i := range decision.Options {
+		if decision.Options[i].ID == optionID {
+			return &decision.Options[i]
+		}
+	}
+	return nil
+}
+
+// requestLifeTriggerDecision 建立生命區觸發效果的選擇
+// 翻開的卡片暫時放在公開區域，由玩家決定是否發動觸發效果
+func (e *gameEngine) requestLifeTriggerDecision(gameState *models.GameState, playerID uuid.UUID, card models.Card, result *ActionResult) {
+	cardID := card.ID
+	e.requestDecision(gameState, models.PendingDecision{
+		Type:     models.DecisionTypeLifeTrigger,
+		PlayerID: playerID,
+		Prompt:   fmt.Sprintf("是否發動「%s」的觸發效果：%s", card.Name, e.getTriggerEffectDescription(card.TriggerEffect, card.Color)),
+		Options: []models.DecisionOption{
+			{ID: "activate", Label: "發動"},
+			{ID: "decline", Label: "不發動"},
+		},
+		MinChoices:     1,
+		MaxChoices:     1,
+		DefaultChoices: []string{"activate"},
+		SourceCardID:   &cardID,
+	}, result)
+}
+
+// resolveLifeTriggerDecision 處理生命區觸發效果的選擇
+// 選擇發動時應用觸發效果，無論是否發動都將卡片從公開區域放置到場外
+func resolveLifeTriggerDecision(e *gameEngine, gameState *models.GameState, decision *models.PendingDecision, choices []string, result *ActionResult) error {
+	player := gameState.Players[decision.PlayerID]
+
+	cardIndex := -1
+	for i, card := range player.Board.PublicArea {
+		if decision.SourceCardID != nil && card.ID == *decision.SourceCardID {
+			cardIndex = i
+			break
+		}
+	}
+	if cardIndex == -1 {
+		return fmt.Errorf("revealed life card not found")
+	}
+
+	// 卡片在效果處理期間留在公開區域，讓效果可以判斷卡片所屬的玩家
+	card := player.Board.PublicArea[cardIndex]
+
+	if choices[0] == "activate" {
+		effect, ok := lifeTriggerEffect(card, decision.PlayerID)
+		if !ok {
+			return fmt.Errorf("trigger effect %s cannot be activated", card.TriggerEffect)
+		}
+		effect.Description = e.getTriggerEffectDescription(card.TriggerEffect, card.Color)
+
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "TRIGGER_EFFECT",
+			Source:    &decision.PlayerID,
+			Data:      map[string]interface{}{"card": card, "effect": effect},
+			Timestamp: e.clock.Now(),
+		})
+
+		if err := e.effectManager.ApplyEffect(context.Background(), gameState, &effect, &card, result); err != nil {
+			return fmt.Errorf("failed to apply trigger effect: %w", err)
+		}
+	}
+
+	for i, revealed := range player.Board.PublicArea {
+		if revealed.ID == card.ID {
+			player.Board.PublicArea = append(player.Board.PublicArea[:i], player.Board.PublicArea[i+1:]...)
+			break
+		}
+	}
+	player.Board.Graveyard = append(player.Board.Graveyard, card)
+	return nil
+}
+
+// lifeTriggerEffect 將生命區卡片的觸發效果轉換為由效果處理器執行的效果
+// 尚未有對應處理器的觸發效果返回 false，這類卡片不提供發動的選擇
+func lifeTriggerEffect(card models.Card, playerID uuid.UUID) (models.CardEffect, bool) {
+	switch card.TriggerEffect {
+	case models.TriggerEffectDrawCard:
+		return models.CardEffect{
+			Type:   "draw",
+			Action: map[string]interface{}{"target": playerID.String()},
+			Value:  float64(1),
+		}, true
+	default:
+		return models.CardEffect{}, false
+	}
+}
+
+// requestHandLimitDecision 檢查手牌上限並建立棄牌選擇
+// 若手牌超過上限，玩家必須選擇多餘的手牌放置到移除區；返回是否建立了選擇
+func (e *gameEngine) requestHandLimitDecision(gameState *models.GameState, playerID uuid.UUID, result *ActionResult) bool {
+	player := gameState.Players[playerID]
+	excess := len(player.Hand) - handLimit
+	if excess <= 0 {
+		return false
+	}
+
+	options := make([]models.DecisionOption, 0, len(player.Hand))
+	for _, card := range player.Hand {
+		cardID := card.ID
+		options = append(options, models.DecisionOption{ID: card.ID.String(), Label: card.Name, CardID: &cardID})
+	}
+
+	// 超過期限時移除最後面的牌
+	defaults := make([]string, 0, excess)
+	for _, card := range player.Hand[handLimit:] {
+		defaults = append(defaults, card.ID.String())
+	}
+
+	e.requestDecision(gameState, models.PendingDecision{
+		Type:           models.DecisionTypeDiscard,
+		PlayerID:       playerID,
+		Prompt:         fmt.Sprintf("手牌超過%d張，請選擇%d張手牌放置到移除區", handLimit, excess),
+		Options:        options,
+		MinChoices:     excess,
+		MaxChoices:     excess,
+		DefaultChoices: defaults,
+		Context:        map[string]string{"then": "end_turn", "excess": strconv.Itoa(excess)},
+	}, result)
+
+	return true
+}
+
+// resolveDiscardDecision 處理棄牌選擇
+// 將選擇的手牌放置到移除區，若是結束回合時的手牌調整則繼續推進到下一回合
+func resolveDiscardDecision(e *gameEngine, gameState *models.GameState, decision *models.PendingDecision, choices []string, result *ActionResult) error {
+	player := gameState.Players[decision.PlayerID]
+
+	chosen := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		chosen[choice] = true
+	}
+
+	remaining := make([]models.Card, 0, len(player.Hand))
+	removed := []models.Card{}
+	for _, card := range player.Hand {
+		if chosen[card.ID.String()] {
+			removed = append(removed, card)
+		} else {
+			remaining = append(remaining, card)
+		}
+	}
+	if len(removed) != len(choices) {
+		return fmt.Errorf("chosen cards are no longer in hand")
+	}
+
+	player.Hand = remaining
+	player.Board.RemoveArea = append(player.Board.RemoveArea, removed...)
+
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:      "CARDS_DISCARDED",
+		Source:    &decision.PlayerID,
+		Data:      map[string]interface{}{"cards": removed, "remaining_hand": len(player.Hand)},
+		Timestamp: e.clock.Now(),
+	})
+
+	if decision.Context["then"] == "end_turn" {
+		e.finishTurn(gameState, result)
+	}
+
+	return nil
+}
@@ -2,8 +2,8 @@ package engine
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"ua/shared/logger"
 	"ua/shared/models"
@@ -13,24 +13,31 @@ import (
 )
 
 type EffectManager interface {
-	ApplyEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error
-	ProcessTriggers(ctx context.Context, gameState *models.GameState, triggerType string, triggerData map[string]interface{}) error
+	ApplyEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error
 	CheckCondition(ctx context.Context, gameState *models.GameState, condition map[string]interface{}) bool
 }
 
 type effectManager struct {
 	effectProcessors map[string]EffectProcessor
+	dealPlayerDamage PlayerDamageFunc
+	clock            Clock
 }
 
 type EffectProcessor interface {
-	Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error
+	Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error
 }
 
 // NewEffectManager 創建新的效果管理器實例
-// 初始化效果處理器映射並註冊所有可用的效果處理器
-func NewEffectManager() EffectManager {
+// 初始化效果處理器映射並註冊所有可用的效果處理器，dealPlayerDamage 用於對玩家造成傷害的效果
+func NewEffectManager(dealPlayerDamage PlayerDamageFunc, clock Clock) EffectManager {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
 	em := &effectManager{
 		effectProcessors: make(map[string]EffectProcessor),
+		dealPlayerDamage: dealPlayerDamage,
+		clock:            clock,
 	}
 
 	em.registerEffectProcessors()
@@ -40,21 +47,22 @@ func NewEffectManager() EffectManager {
 // registerEffectProcessors 註冊所有可用的效果處理器
 // 將各種卡牌效果類型映射到對應的處理器實例
 func (em *effectManager) registerEffectProcessors() {
-	em.effectProcessors["damage"] = &DamageEffectProcessor{}
+	em.effectProcessors["damage"] = &DamageEffectProcessor{dealPlayerDamage: em.dealPlayerDamage, clock: em.clock}
 	em.effectProcessors["heal"] = &HealEffectProcessor{}
 	em.effectProcessors["draw"] = &DrawEffectProcessor{}
 	em.effectProcessors["search"] = &SearchEffectProcessor{}
 	em.effectProcessors["boost"] = &BoostEffectProcessor{}
-	em.effectProcessors["debuff"] = &DebuffEffectProcessor{}
+	em.effectProcessors["debuff"] = &DebuffEffectProcessor{clock: em.clock}
 	em.effectProcessors["summon"] = &SummonEffectProcessor{}
-	em.effectProcessors["destroy"] = &DestroyEffectProcessor{}
+	em.effectProcessors["destroy"] = &DestroyEffectProcessor{clock: em.clock}
 	em.effectProcessors["move"] = &MoveEffectProcessor{}
 	em.effectProcessors["energy"] = &EnergyEffectProcessor{}
+	em.effectProcessors["restrict"] = &RestrictEffectProcessor{}
 }
 
 // ApplyEffect 應用卡牌效果到遊戲狀態
-// 首先檢查效果條件是否滿足，然後找到對應的處理器來執行效果
-func (em *effectManager) ApplyEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+// 首先檢查效果條件是否滿足，然後找到對應的處理器來執行效果，效果結果記錄到 result 中
+func (em *effectManager) ApplyEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
 	if effect.Condition != nil && !em.CheckCondition(ctx, gameState, effect.Condition) {
 		logger.Debug("Effect condition not met",
 			zap.String("effect_type", effect.Type),
@@ -72,35 +80,7 @@ func (em *effectManager) ApplyEffect(ctx context.Context, gameState *models.Game
 		zap.String("source_card", sourceCard.Name),
 		zap.String("description", effect.Description))
 
-	return processor.Process(ctx, gameState, effect, sourceCard)
-}
-
-// ProcessTriggers 處理觸發效果
-// 檢查場上所有卡牌是否有符合觸發條件的效果，並執行符合條件的效果
-func (em *effectManager) ProcessTriggers(ctx context.Context, gameState *models.GameState, triggerType string, triggerData map[string]interface{}) error {
-	for _, player := range gameState.Players {
-		allCards := append(player.Board.FrontLine, player.Board.EnergyLine...)
-
-		for _, cardInPlay := range allCards {
-			if cardInPlay.Card.TriggerEffect != "" && cardInPlay.Card.TriggerEffect != models.TriggerEffectNil {
-				// Convert simple trigger effect string to CardEffect struct
-				effect := models.CardEffect{
-					Type:        cardInPlay.Card.TriggerEffect,
-					Description: em.getTriggerEffectDescription(cardInPlay.Card.TriggerEffect, cardInPlay.Card.Color),
-				}
-
-				if em.shouldTrigger(&effect, triggerType, triggerData) {
-					if err := em.ApplyEffect(ctx, gameState, &effect, &cardInPlay.Card); err != nil {
-						logger.Error("Failed to apply triggered effect",
-							zap.Error(err),
-							zap.String("card", cardInPlay.Card.Name))
-					}
-				}
-			}
-		}
-	}
-
-	return nil
+	return processor.Process(ctx, gameState, effect, sourceCard, result)
 }
 
 // CheckCondition 檢查效果觸發條件是否滿足
@@ -128,21 +108,6 @@ func (em *effectManager) CheckCondition(ctx context.Context, gameState *models.G
 	}
 }
 
-// shouldTrigger 判斷效果是否應該被觸發
-// 檢查效果的觸發類型是否與當前觸發類型匹配
-func (em *effectManager) shouldTrigger(effect *models.CardEffect, triggerType string, triggerData map[string]interface{}) bool {
-	if effect.Action == nil {
-		return false
-	}
-
-	effectTrigger, exists := effect.Action["trigger"].(string)
-	if !exists {
-		return false
-	}
-
-	return effectTrigger == triggerType
-}
-
 // checkCharacterCountCondition 檢查角色數量條件
 // 驗證指定玩家的角色數量是否在設定的範圍內
 func (em *effectManager) checkCharacterCountCondition(gameState *models.GameState, condition map[string]interface{}) bool {
@@ -198,18 +163,14 @@ func (em *effectManager) checkEnergyCondition(gameState *models.GameState, condi
 		return false
 	}
 
+	cost := make(map[string]int, len(requiredEnergy))
 	for color, requiredAmount := range requiredEnergy {
-		required, ok := requiredAmount.(float64)
-		if !ok {
-			continue
-		}
-
-		if float64(player.Energy[color]) < required {
-			return false
+		if required, ok := requiredAmount.(float64); ok {
+			cost[color] = int(required)
 		}
 	}
 
-	return true
+	return len(models.CheckEnergyRequirement(player.AvailableEnergy(), cost)) == 0
 }
 
 // checkTurnCondition 檢查回合數條件
@@ -241,13 +202,131 @@ func (em *effectManager) checkHealthCondition(gameState *models.GameState, condi
 	return true
 }
 
-type DamageEffectProcessor struct{}
+// PlayerDamageFunc 對玩家造成傷害的規則處理
+// 由遊戲引擎提供，負責翻開生命區並建立觸發效果的選擇
+type PlayerDamageFunc func(gameState *models.GameState, playerID uuid.UUID, damage int, result *ActionResult)
+
+// recordEffect 記錄效果結果
+// 將效果結果加入動作結果中，result 為nil時忽略
+func recordEffect(result *ActionResult, effectResult EffectResult) {
+	if result == nil {
+		return
+	}
+	result.Effects = append(result.Effects, effectResult)
+}
+
+// recordEvent 記錄效果觸發的遊戲事件
+// 將事件加入動作結果中，result 為nil時忽略
+func recordEvent(result *ActionResult, event GameEvent) {
+	if result == nil {
+		return
+	}
+	result.EventsTriggered = append(result.EventsTriggered, event)
+}
+
+// findEffectTargetCard 尋找效果指定的場上卡片
+// 從 action.target 讀取卡片ID，並在所有玩家的前線與能源線中尋找，返回卡片擁有者與卡片
+func findEffectTargetCard(gameState *models.GameState, effect *models.CardEffect, effectType string) (*models.Player, *models.CardInPlay, error) {
+	targetID, exists := effect.Action["target"].(string)
+	if !exists {
+		return nil, nil, fmt.Errorf("target required for %s effect", effectType)
+	}
+
+	targetCardID, err := uuid.Parse(targetID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid target card ID")
+	}
+
+	for _, player := range gameState.Players {
+		if card := findCardOnBoard(player, targetCardID); card != nil {
+			return player, card, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("target card not found on board")
+}
+
+// findEffectTargetPlayer 尋找效果指定的玩家
+// 從 action.target 讀取玩家ID並確認玩家存在於遊戲中
+func findEffectTargetPlayer(gameState *models.GameState, effect *models.CardEffect, effectType string) (uuid.UUID, *models.Player, error) {
+	targetPlayerStr, exists := effect.Action["target"].(string)
+	if !exists {
+		return uuid.Nil, nil, fmt.Errorf("target required for %s effect", effectType)
+	}
+
+	targetPlayerID, err := uuid.Parse(targetPlayerStr)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("invalid target player ID")
+	}
+
+	player, exists := gameState.Players[targetPlayerID]
+	if !exists {
+		return uuid.Nil, nil, fmt.Errorf("target player not found")
+	}
+
+	return targetPlayerID, player, nil
+}
+
+// effectPlayerOrder 獲取效果處理玩家的順序
+// 先處理回合玩家，再處理其他玩家，讓同時影響雙方的效果產生固定順序的結果
+func effectPlayerOrder(gameState *models.GameState) []uuid.UUID {
+	order := []uuid.UUID{}
+	if _, exists := gameState.Players[gameState.ActivePlayer]; exists {
+		order = append(order, gameState.ActivePlayer)
+	}
+	for playerID := range gameState.Players {
+		if playerID != gameState.ActivePlayer {
+			order = append(order, playerID)
+		}
+	}
+	return order
+}
+
+// applyBPModifier 為角色添加BP修正器
+// 根據Union Arena規則，BP因效果降至0以下的角色立即退場；返回角色是否退場
+func applyBPModifier(gameState *models.GameState, owner *models.Player, target *models.CardInPlay, modifier models.CardModifier, effectType string, now time.Time, result *ActionResult) bool {
+	cardID := target.Card.ID
+	cardName := target.Card.Name
+
+	target.Modifiers = append(target.Modifiers, modifier)
+	bp := EffectiveBP(target, gameState)
+	retired := bp <= 0
+
+	description := fmt.Sprintf("「%s」BP%+d，目前BP %d", cardName, modifier.Value, bp)
+	if retired {
+		retireCharacter(gameState, owner, cardID)
+		description = fmt.Sprintf("「%s」BP降至0，角色退場", cardName)
+
+		recordEvent(result, GameEvent{
+			Type:      "CHARACTER_DESTROYED",
+			Target:    &cardID,
+			Data:      map[string]interface{}{"reason": "bp_zero", "effect_type": effectType},
+			Timestamp: now,
+		})
+	}
+
+	recordEffect(result, EffectResult{
+		Type:        effectType,
+		Source:      modifier.Source,
+		Target:      &cardID,
+		Value:       modifier.Value,
+		Description: description,
+		Applied:     true,
+	})
+
+	return retired
+}
+
+type DamageEffectProcessor struct {
+	dealPlayerDamage PlayerDamageFunc
+	clock            Clock
+}
 
 // Process 處理傷害效果
 // 根據目標類型對指定目標造成傷害
-func (p *DamageEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+func (p *DamageEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
 	damage, ok := effect.Value.(float64)
-	if !ok {
+	if !ok || damage <= 0 {
 		return fmt.Errorf("invalid damage value")
 	}
 
@@ -258,39 +337,154 @@ func (p *DamageEffectProcessor) Process(ctx context.Context, gameState *models.G
 
 	switch targetType {
 	case "character":
-		return p.damageCharacter(gameState, effect, int(damage))
+		return p.damageCharacter(gameState, effect, sourceCard, int(damage), result)
 	case "player":
-		return p.damagePlayer(gameState, effect, int(damage))
+		return p.damagePlayer(gameState, effect, sourceCard, int(damage), result)
 	case "all_characters":
-		return p.damageAllCharacters(gameState, effect, int(damage))
+		return p.damageAllCharacters(gameState, effect, sourceCard, int(damage), result)
 	default:
 		return fmt.Errorf("unknown target_type: %s", targetType)
 	}
 }
 
 // damageCharacter 對指定角色造成傷害
-// 目前暫時返回nil，待後續實現具體傷害邏輯
-func (p *DamageEffectProcessor) damageCharacter(gameState *models.GameState, effect *models.CardEffect, damage int) error {
+// 傷害以BP減少表示並持續到角色離場，BP降至0以下的角色立即退場
+func (p *DamageEffectProcessor) damageCharacter(gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, damage int, result *ActionResult) error {
+	owner, target, err := findEffectTargetCard(gameState, effect, "damage")
+	if err != nil {
+		return err
+	}
+	if target.Card.BP == nil {
+		return fmt.Errorf("damage target is not a character")
+	}
+
+	applyBPModifier(gameState, owner, target, models.CardModifier{
+		Type:       models.ModifierTypeBP,
+		Value:      -damage,
+		Scope:      models.ModifierScopeWhileOnBoard,
+		Source:     sourceCard.ID,
+		Controller: findCardController(gameState, sourceCard.ID),
+		AppliedAt:  gameState.Turn,
+	}, "damage", p.clock.Now(), result)
+
 	return nil
 }
 
 // damagePlayer 對指定玩家造成傷害
-// 目前暫時返回nil，待後續實現具體傷害邏輯
-func (p *DamageEffectProcessor) damagePlayer(gameState *models.GameState, effect *models.CardEffect, damage int) error {
+// 由遊戲引擎從生命區翻開等同傷害數量的卡片並處理觸發效果
+func (p *DamageEffectProcessor) damagePlayer(gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, damage int, result *ActionResult) error {
+	if p.dealPlayerDamage == nil {
+		return fmt.Errorf("player damage is not supported")
+	}
+
+	targetPlayerID, player, err := findEffectTargetPlayer(gameState, effect, "damage")
+	if err != nil {
+		return err
+	}
+
+	p.dealPlayerDamage(gameState, targetPlayerID, damage, result)
+
+	recordEffect(result, EffectResult{
+		Type:        "damage",
+		Source:      sourceCard.ID,
+		Target:      &targetPlayerID,
+		Value:       damage,
+		Description: fmt.Sprintf("對玩家造成%d點傷害，剩餘生命%d", damage, len(player.Board.LifeArea)),
+		Applied:     true,
+	})
+
 	return nil
 }
 
 // damageAllCharacters 對所有角色造成傷害
-// 目前暫時返回nil，待後續實現具體傷害邏輯
-func (p *DamageEffectProcessor) damageAllCharacters(gameState *models.GameState, effect *models.CardEffect, damage int) error {
+// 對前線與能源線上的所有角色造成傷害，可用 action.player 限定只影響指定玩家的角色
+func (p *DamageEffectProcessor) damageAllCharacters(gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, damage int, result *ActionResult) error {
+	var onlyPlayer *uuid.UUID
+	if playerStr, exists := effect.Action["player"].(string); exists {
+		playerID, err := uuid.Parse(playerStr)
+		if err != nil {
+			return fmt.Errorf("invalid player ID")
+		}
+		if _, exists := gameState.Players[playerID]; !exists {
+			return fmt.Errorf("player not found")
+		}
+		onlyPlayer = &playerID
+	}
+
+	for _, playerID := range effectPlayerOrder(gameState) {
+		if onlyPlayer != nil && playerID != *onlyPlayer {
+			continue
+		}
+		player := gameState.Players[playerID]
+
+		// 先收集目標，避免退場時修改正在迭代的區域
+		targets := []uuid.UUID{}
+		for _, line := range [][]models.CardInPlay{player.Board.FrontLine, player.Board.EnergyLine} {
+			for _, character := range line {
+				if character.Card.BP != nil {
+					targets = append(targets, character.Card.ID)
+				}
+			}
+		}
+
+		for _, targetID := range targets {
+			target := findCardOnBoard(player, targetID)
+			if target == nil {
+				continue
+			}
+			applyBPModifier(gameState, player, target, models.CardModifier{
+				Type:       models.ModifierTypeBP,
+				Value:      -damage,
+				Scope:      models.ModifierScopeWhileOnBoard,
+				Source:     sourceCard.ID,
+				Controller: findCardController(gameState, sourceCard.ID),
+				AppliedAt:  gameState.Turn,
+			}, "damage", p.clock.Now(), result)
+		}
+	}
+
 	return nil
 }
 
 type HealEffectProcessor struct{}
 
 // Process 處理治療效果
-// 目前暫時返回nil，待後續實現治療邏輯
-func (p *HealEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+// 從目標玩家卡組頂部將指定數量的卡片背面朝上放置到生命區頂部
+func (p *HealEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
+	count, ok := effect.Value.(float64)
+	if !ok {
+		count = 1
+	}
+	if count <= 0 {
+		return fmt.Errorf("invalid heal value")
+	}
+
+	targetPlayerID, player, err := findEffectTargetPlayer(gameState, effect, "heal")
+	if err != nil {
+		return err
+	}
+
+	healed := 0
+	for healed < int(count) && len(player.Deck) > 0 {
+		card := player.Deck[0]
+		player.Deck = player.Deck[1:]
+		player.Board.LifeArea = append([]models.Card{card}, player.Board.LifeArea...)
+		healed++
+	}
+
+	recordEffect(result, EffectResult{
+		Type:        "heal",
+		Source:      sourceCard.ID,
+		Target:      &targetPlayerID,
+		Value:       healed,
+		Description: fmt.Sprintf("生命區增加%d張，目前生命%d", healed, len(player.Board.LifeArea)),
+		Applied:     healed > 0,
+	})
+
+	logger.Debug("Life area healed",
+		zap.String("player", targetPlayerID.String()),
+		zap.Int("count", healed))
+
 	return nil
 }
 
@@ -298,25 +492,15 @@ type DrawEffectProcessor struct{}
 
 // Process 處理抽牌效果
 // 讓指定玩家從卡組中抽取指定數量的卡牌到手牌
-func (p *DrawEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+func (p *DrawEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
 	count, ok := effect.Value.(float64)
 	if !ok {
 		count = 1
 	}
 
-	targetPlayerStr, exists := effect.Action["target"].(string)
-	if !exists {
-		return fmt.Errorf("target required for draw effect")
-	}
-
-	targetPlayerID, err := uuid.Parse(targetPlayerStr)
+	targetPlayerID, player, err := findEffectTargetPlayer(gameState, effect, "draw")
 	if err != nil {
-		return fmt.Errorf("invalid target player ID")
-	}
-
-	player, exists := gameState.Players[targetPlayerID]
-	if !exists {
-		return fmt.Errorf("target player not found")
+		return err
 	}
 
 	cardsDrawn := 0
@@ -326,6 +510,18 @@ func (p *DrawEffectProcessor) Process(ctx context.Context, gameState *models.Gam
 		player.Hand = append(player.Hand, card)
 		cardsDrawn++
 	}
+	if cardsDrawn > 0 {
+		emitTiming(gameState, models.TimingOnDraw, targetPlayerID, nil, map[string]interface{}{"count": cardsDrawn})
+	}
+
+	recordEffect(result, EffectResult{
+		Type:        "draw",
+		Source:      sourceCard.ID,
+		Target:      &targetPlayerID,
+		Value:       cardsDrawn,
+		Description: fmt.Sprintf("抽%d張牌", cardsDrawn),
+		Applied:     cardsDrawn > 0,
+	})
 
 	logger.Debug("Cards drawn",
 		zap.String("player", targetPlayerID.String()),
@@ -338,20 +534,25 @@ type SearchEffectProcessor struct{}
 
 // Process 處理搜尋效果
 // 目前暫時返回nil，待後續實現搜尋邏輯
-func (p *SearchEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+func (p *SearchEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
 	return nil
 }
 
 type BoostEffectProcessor struct{}
 
 // Process 處理增強效果
-// 為指定角色添加BP增強修正器
-func (p *BoostEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+// 為指定角色添加BP增強修正器，持續範圍由 action.scope 指定（預設為直到角色離場）
+func (p *BoostEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
 	boost, ok := effect.Value.(float64)
 	if !ok {
 		return fmt.Errorf("invalid boost value")
 	}
 
+	scope, err := parseModifierScope(effect, models.ModifierScopeWhileOnBoard)
+	if err != nil {
+		return err
+	}
+
 	targetID, exists := effect.Action["target"].(string)
 	if !exists {
 		return fmt.Errorf("target required for boost effect")
@@ -366,13 +567,23 @@ func (p *BoostEffectProcessor) Process(ctx context.Context, gameState *models.Ga
 		for i, character := range player.Board.FrontLine {
 			if character.Card.ID == targetCardID {
 				modifier := models.CardModifier{
-					Type:      "bp_boost",
-					Value:     int(boost),
-					Duration:  -1,
-					Source:    sourceCard.ID,
-					AppliedAt: gameState.Turn,
+					Type:       models.ModifierTypeBP,
+					Value:      int(boost),
+					Scope:      scope,
+					Source:     sourceCard.ID,
+					Controller: findCardController(gameState, sourceCard.ID),
+					AppliedAt:  gameState.Turn,
 				}
 				player.Board.FrontLine[i].Modifiers = append(player.Board.FrontLine[i].Modifiers, modifier)
+
+				recordEffect(result, EffectResult{
+					Type:        "boost",
+					Source:      sourceCard.ID,
+					Target:      &targetCardID,
+					Value:       int(boost),
+					Description: fmt.Sprintf("「%s」BP%+d", character.Card.Name, int(boost)),
+					Applied:     true,
+				})
 				return nil
 			}
 		}
@@ -381,35 +592,242 @@ func (p *BoostEffectProcessor) Process(ctx context.Context, gameState *models.Ga
 	return fmt.Errorf("target character not found")
 }
 
-type DebuffEffectProcessor struct{}
+type DebuffEffectProcessor struct {
+	clock Clock
+}
 
 // Process 處理弱化效果
-// 目前暫時返回nil，待後續實現弱化邏輯
-func (p *DebuffEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+// 為指定角色添加BP減少修正器，持續範圍由 action.scope 指定（預設為本回合），BP降至0以下的角色立即退場
+func (p *DebuffEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
+	amount, ok := effect.Value.(float64)
+	if !ok || amount <= 0 {
+		return fmt.Errorf("invalid debuff value")
+	}
+
+	scope, err := parseModifierScope(effect, models.ModifierScopeThisTurn)
+	if err != nil {
+		return err
+	}
+
+	owner, target, err := findEffectTargetCard(gameState, effect, "debuff")
+	if err != nil {
+		return err
+	}
+	if target.Card.BP == nil {
+		return fmt.Errorf("debuff target is not a character")
+	}
+
+	applyBPModifier(gameState, owner, target, models.CardModifier{
+		Type:       models.ModifierTypeBP,
+		Value:      -int(amount),
+		Scope:      scope,
+		Source:     sourceCard.ID,
+		Controller: findCardController(gameState, sourceCard.ID),
+		AppliedAt:  gameState.Turn,
+	}, "debuff", p.clock.Now(), result)
+
 	return nil
 }
 
 type SummonEffectProcessor struct{}
 
 // Process 處理召喚效果
-// 目前暫時返回nil，待後續實現召喚邏輯
-func (p *SummonEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+// 將目標玩家手牌、卡組或場外區中指定的卡片以休息狀態登場，不需支付AP與能源
+func (p *SummonEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
+	targetPlayerID, player, err := findEffectTargetPlayer(gameState, effect, "summon")
+	if err != nil {
+		return err
+	}
+
+	cardIDStr, exists := effect.Action["card"].(string)
+	if !exists {
+		return fmt.Errorf("card required for summon effect")
+	}
+	cardID, err := uuid.Parse(cardIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid summon card ID")
+	}
+
+	from, exists := effect.Action["from"].(string)
+	if !exists {
+		from = "hand"
+	}
+	zone, exists := effect.Action["zone"].(string)
+	if !exists {
+		zone = "front_line"
+	}
+
+	var pile *[]models.Card
+	switch from {
+	case "hand":
+		pile = &player.Hand
+	case "deck":
+		pile = &player.Deck
+	case "outside_area":
+		pile = &player.Board.OutsideArea
+	default:
+		return fmt.Errorf("unknown summon source: %s", from)
+	}
+
+	cardIndex := -1
+	for i, card := range *pile {
+		if card.ID == cardID {
+			cardIndex = i
+			break
+		}
+	}
+	if cardIndex == -1 {
+		return fmt.Errorf("summon card not found in %s", from)
+	}
+	card := (*pile)[cardIndex]
+
+	if card.CardType != models.CardTypeCharacter && card.CardType != models.CardTypeField {
+		return fmt.Errorf("only character and field cards can be summoned")
+	}
+	if card.CardType == models.CardTypeField && zone != "energy_line" {
+		return fmt.Errorf("field cards can only be placed in the energy line")
+	}
+
+	var line *[]models.CardInPlay
+	switch zone {
+	case "front_line":
+		line = &player.Board.FrontLine
+	case "energy_line":
+		line = &player.Board.EnergyLine
+	default:
+		return fmt.Errorf("invalid summon zone: %s", zone)
+	}
+	slot, err := availableSlot(*line, nil, nil)
+	if err != nil {
+		return fmt.Errorf("%s is full", zone)
+	}
+
+	*pile = append((*pile)[:cardIndex], (*pile)[cardIndex+1:]...)
+	*line = append(*line, models.CardInPlay{
+		Card:       card,
+		Position:   models.Position{Zone: zone, Slot: slot},
+		Status:     models.CardStatus{CanAct: false, CanAttack: false, CanBlock: true, IsActive: false, IsRested: true},
+		Modifiers:  []models.CardModifier{},
+		Owner:      targetPlayerID,
+		PlayedTurn: gameState.Turn,
+	})
+
+	recordEffect(result, EffectResult{
+		Type:        "summon",
+		Source:      sourceCard.ID,
+		Target:      &cardID,
+		Value:       zone,
+		Description: fmt.Sprintf("「%s」登場", card.Name),
+		Applied:     true,
+	})
+
 	return nil
 }
 
-type DestroyEffectProcessor struct{}
+type DestroyEffectProcessor struct {
+	clock Clock
+}
 
 // Process 處理摧毀效果
-// 目前暫時返回nil，待後續實現摧毀邏輯
-func (p *DestroyEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+// 使指定的場上卡片退場並放置到場外區
+func (p *DestroyEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
+	owner, target, err := findEffectTargetCard(gameState, effect, "destroy")
+	if err != nil {
+		return err
+	}
+
+	cardID := target.Card.ID
+	cardName := target.Card.Name
+	retireCharacter(gameState, owner, cardID)
+
+	recordEvent(result, GameEvent{
+		Type:      "CHARACTER_DESTROYED",
+		Target:    &cardID,
+		Data:      map[string]interface{}{"reason": "effect", "source_card": sourceCard.ID},
+		Timestamp: p.clock.Now(),
+	})
+
+	recordEffect(result, EffectResult{
+		Type:        "destroy",
+		Source:      sourceCard.ID,
+		Target:      &cardID,
+		Description: fmt.Sprintf("「%s」退場", cardName),
+		Applied:     true,
+	})
+
 	return nil
 }
 
 type MoveEffectProcessor struct{}
 
 // Process 處理移動效果
-// 目前暫時返回nil，待後續實現移動邏輯
-func (p *MoveEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+// 將指定的場上卡片移動到另一條線、手牌、卡組頂部、場外區或移除區，離開場上的移動不視為退場
+func (p *MoveEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
+	zone, exists := effect.Action["zone"].(string)
+	if !exists {
+		return fmt.Errorf("zone required for move effect")
+	}
+
+	owner, target, err := findEffectTargetCard(gameState, effect, "move")
+	if err != nil {
+		return err
+	}
+	cardID := target.Card.ID
+	cardName := target.Card.Name
+
+	currentZone := "energy_line"
+	if findCardInLine(owner.Board.FrontLine, cardID) != nil {
+		currentZone = "front_line"
+	}
+
+	switch zone {
+	case "front_line", "energy_line":
+		if zone == currentZone {
+			return fmt.Errorf("card is already in %s", zone)
+		}
+		if target.Card.CardType == models.CardTypeField && zone == "front_line" {
+			return fmt.Errorf("field cards cannot move to the front line")
+		}
+
+		line := &owner.Board.EnergyLine
+		if zone == "front_line" {
+			line = &owner.Board.FrontLine
+		}
+		slot, err := availableSlot(*line, nil, nil)
+		if err != nil {
+			return fmt.Errorf("%s is full", zone)
+		}
+
+		moved, _ := removeCardFromBoard(owner, cardID)
+		moved.Position = models.Position{Zone: zone, Slot: slot}
+		moved.Status.CanAttack = zone == "front_line" && moved.Status.IsActive
+		*line = append(*line, moved)
+	case "hand", "deck", "outside_area", "remove_area":
+		moved, _ := removeCardFromBoard(owner, cardID)
+		releaseStack(owner, &moved)
+		switch zone {
+		case "hand":
+			owner.Hand = append(owner.Hand, moved.Card)
+		case "deck":
+			owner.Deck = append([]models.Card{moved.Card}, owner.Deck...)
+		case "outside_area":
+			owner.Board.OutsideArea = append(owner.Board.OutsideArea, moved.Card)
+		case "remove_area":
+			owner.Board.RemoveArea = append(owner.Board.RemoveArea, moved.Card)
+		}
+	default:
+		return fmt.Errorf("invalid move zone: %s", zone)
+	}
+
+	recordEffect(result, EffectResult{
+		Type:        "move",
+		Source:      sourceCard.ID,
+		Target:      &cardID,
+		Value:       map[string]string{"from": currentZone, "to": zone},
+		Description: fmt.Sprintf("「%s」移動到%s", cardName, zone),
+		Applied:     true,
+	})
+
 	return nil
 }
 
@@ -417,35 +835,92 @@ type EnergyEffectProcessor struct{}
 
 // Process 處理能源效果
 // 為指定玩家增加或減少各種顏色的能源
-func (p *EnergyEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
+func (p *EnergyEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
 	energyAmount, ok := effect.Value.(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("invalid energy value")
 	}
 
-	targetPlayerStr, exists := effect.Action["target"].(string)
-	if !exists {
-		return fmt.Errorf("target required for energy effect")
-	}
-
-	targetPlayerID, err := uuid.Parse(targetPlayerStr)
+	targetPlayerID, player, err := findEffectTargetPlayer(gameState, effect, "energy")
 	if err != nil {
-		return fmt.Errorf("invalid target player ID")
+		return err
 	}
 
-	player, exists := gameState.Players[targetPlayerID]
-	if !exists {
-		return fmt.Errorf("target player not found")
+	// 效果給予的能源只在本回合有效，於結束階段清除
+	if player.EnergyBonus == nil {
+		player.EnergyBonus = make(map[string]int)
 	}
-
 	for color, amount := range energyAmount {
 		if amt, ok := amount.(float64); ok {
-			player.Energy[color] += int(amt)
-			if player.Energy[color] < 0 {
-				player.Energy[color] = 0
-			}
+			player.EnergyBonus[color] += int(amt)
 		}
 	}
+	player.Energy = player.AvailableEnergy()
+
+	recordEffect(result, EffectResult{
+		Type:        "energy",
+		Source:      sourceCard.ID,
+		Target:      &targetPlayerID,
+		Value:       energyAmount,
+		Description: "能源變化",
+		Applied:     true,
+	})
+
+	return nil
+}
+
+type RestrictEffectProcessor struct{}
+
+// Process 處理限制效果
+// 為指定角色添加不能攻擊、不能防禦或下一次起始階段不轉為活動狀態的修正，持續範圍由 action.scope 指定（預設為直到對手的下個回合結束）
+func (p *RestrictEffectProcessor) Process(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
+	restriction, exists := effect.Action["restriction"].(string)
+	if !exists {
+		return fmt.Errorf("restriction required for restrict effect")
+	}
+
+	var description string
+	switch restriction {
+	case models.ModifierTypeCannotAttack:
+		description = "不能攻擊"
+	case models.ModifierTypeCannotBlock:
+		description = "不能防禦"
+	case models.ModifierTypeNoActivate:
+		description = "下個起始階段不轉為活動狀態"
+	default:
+		return fmt.Errorf("unknown restriction: %s", restriction)
+	}
+
+	scope, err := parseModifierScope(effect, models.ModifierScopeOpponentNextTurn)
+	if err != nil {
+		return err
+	}
+
+	_, target, err := findEffectTargetCard(gameState, effect, "restrict")
+	if err != nil {
+		return err
+	}
+	if target.Card.BP == nil {
+		return fmt.Errorf("restrict target is not a character")
+	}
+
+	target.Modifiers = append(target.Modifiers, models.CardModifier{
+		Type:       restriction,
+		Scope:      scope,
+		Source:     sourceCard.ID,
+		Controller: findCardController(gameState, sourceCard.ID),
+		AppliedAt:  gameState.Turn,
+	})
+
+	cardID := target.Card.ID
+	recordEffect(result, EffectResult{
+		Type:        "restrict",
+		Source:      sourceCard.ID,
+		Target:      &cardID,
+		Value:       restriction,
+		Description: fmt.Sprintf("「%s」%s", target.Card.Name, description),
+		Applied:     true,
+	})
 
 	return nil
 }
@@ -507,12 +982,7 @@ func (tm *turnManager) ProcessTurnStart(ctx context.Context, gameState *models.G
 	}
 
 	// 重置前線卡片狀態
-	for i := range player.Board.FrontLine {
-		player.Board.FrontLine[i].Status.CanAttack = true
-		player.Board.FrontLine[i].Status.IsActive = true
-		player.Board.FrontLine[i].Status.IsRested = false
-		player.Board.FrontLine[i].Status.CanAct = true
-	}
+	activateCharacters(player)
 	// 重置能源線卡片狀態
 	for i := range player.Board.EnergyLine {
 		player.Board.EnergyLine[i].Status.IsActive = true
@@ -558,20 +1028,10 @@ func (tm *turnManager) ProcessPhaseEnd(ctx context.Context, gameState *models.Ga
 }
 
 // processStartPhase 處理起始階段
-// 計算場域卡產生的能源並添加到玩家的能源池中
+// 根據場上卡片重新計算玩家目前可用的能源
 func (tm *turnManager) processStartPhase(ctx context.Context, gameState *models.GameState) error {
 	player := gameState.Players[gameState.ActivePlayer]
-
-	var energyProduce map[string]int
-	for _, field := range player.Board.EnergyLine {
-		if field.Card.EnergyProduce != nil {
-			json.Unmarshal(field.Card.EnergyProduce, &energyProduce)
-			for color, amount := range energyProduce {
-				player.Energy[color] += amount
-			}
-		}
-	}
-
+	player.Energy = player.AvailableEnergy()
 	return nil
 }
 
@@ -588,45 +1048,23 @@ func (tm *turnManager) processAttackPhase(ctx context.Context, gameState *models
 }
 
 // processEndPhase 處理結束階段
-// 處理結束階段效果、減少修正器持續時間、檢查手牌上限
+// 處理結束階段效果、移除雙方已失效的修正
 func (tm *turnManager) processEndPhase(ctx context.Context, gameState *models.GameState) error {
-	player := gameState.Players[gameState.ActivePlayer]
-
 	// 1. 處理「在結束階段開始時」發動的效果
 	// TODO: 實現結束階段觸發效果
 
 	// 2. 所有註明「在這個回合中」的效果在此時點失效
-	// TODO: 實現回合效果清理
-
-	// 3. 減少所有角色的修正器持續時間，移除已過期的修正器
-	// 處理前線卡片的修正器
-	for i := range player.Board.FrontLine {
-		for j := len(player.Board.FrontLine[i].Modifiers) - 1; j >= 0; j-- {
-			modifier := &player.Board.FrontLine[i].Modifiers[j]
-			if modifier.Duration > 0 {
-				modifier.Duration--
-				if modifier.Duration == 0 {
-					player.Board.FrontLine[i].Modifiers = append(
-						player.Board.FrontLine[i].Modifiers[:j],
-						player.Board.FrontLine[i].Modifiers[j+1:]...)
-				}
-			}
-		}
+	// 效果給予的本回合能源
+	for _, p := range gameState.Players {
+		p.EnergyBonus = nil
+		p.Energy = p.AvailableEnergy()
 	}
 
+	// 3. 移除雙方角色已失效的修正
+	expireModifiers(gameState)
+
 	// 4. 調整手牌：若自己的手牌超過8張，必須選擇多餘的手牌放置到移除區
-	if len(player.Hand) > 8 {
-		cardsToRemove := len(player.Hand) - 8
-		// 目前自動移除最後面的牌，實際應該由玩家選擇
-		removedCards := player.Hand[8:]
-		player.Hand = player.Hand[:8]
-		player.Board.RemoveArea = append(player.Board.RemoveArea, removedCards...)
-
-		logger.Debug("Hand limit exceeded, cards removed",
-			zap.String("player", gameState.ActivePlayer.String()),
-			zap.Int("cards_removed", cardsToRemove),
-			zap.Int("remaining_hand", len(player.Hand)))
-	}
+	// 由玩家透過待決選擇（DISCARD）決定，見 gameEngine.requestHandLimitDecision
 
 	return nil
 }
@@ -0,0 +1,310 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxTriggerResolutions 一次處理中最多處理的觸發效果數量，避免互相觸發的能力無限循環
+const maxTriggerResolutions = 100
+
+// TriggerSubscriber 訂閱遊戲時點的處理器
+// 返回因該事件而觸發的效果，不觸發時返回nil
+type TriggerSubscriber func(gameState *models.GameState, event models.TimingEvent) []models.PendingTrigger
+
+// EventBus 遊戲時點的事件匯流排
+// 將引擎發出的時點事件分派給訂閱該時點的處理器
+type EventBus struct {
+	subscribers map[string][]TriggerSubscriber
+}
+
+// NewEventBus 創建新的事件匯流排
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[string][]TriggerSubscriber)}
+}
+
+// Subscribe 訂閱指定的遊戲時點
+func (b *EventBus) Subscribe(timing string, subscriber TriggerSubscriber) {
+	b.subscribers[timing] = append(b.subscribers[timing], subscriber)
+}
+
+// Collect 收集事件觸發的所有效果
+// 依訂閱順序呼叫處理器並合併結果
+func (b *EventBus) Collect(gameState *models.GameState, event models.TimingEvent) []models.PendingTrigger {
+	triggers := []models.PendingTrigger{}
+	for _, subscriber := range b.subscribers[event.Timing] {
+		triggers = append(triggers, subscriber(gameState, event)...)
+	}
+	return triggers
+}
+
+// registerTriggerSubscribers 註冊所有時點的訂閱者
+// 卡片能力訂閱所有時點
+func (e *gameEngine) registerTriggerSubscribers() {
+	for _, timing := range models.TimingPoints() {
+		e.eventBus.Subscribe(timing, cardAbilityTriggers)
+	}
+}
+
+// emitTiming 發出遊戲時點事件
+// 事件先加入遊戲狀態的佇列，於動作處理完成後一起收集觸發效果，因此同一個動作中發出的事件視為同時發生
+func emitTiming(gameState *models.GameState, timing string, playerID uuid.UUID, card *models.Card, data map[string]interface{}) {
+	event := models.TimingEvent{
+		Timing:   timing,
+		PlayerID: playerID,
+		Data:     data,
+	}
+	if card != nil {
+		snapshot := *card
+		event.Card = &snapshot
+	}
+	gameState.TimingEvents = append(gameState.TimingEvents, event)
+}
+
+// collectTriggers 收集佇列中時點事件觸發的效果
+// 同時觸發的效果依回合玩家優先排序，同一玩家的效果維持觸發順序，並加入待處理佇列的尾端
+func (e *gameEngine) collectTriggers(gameState *models.GameState) {
+	if len(gameState.TimingEvents) == 0 {
+		return
+	}
+
+	events := gameState.TimingEvents
+	gameState.TimingEvents = nil
+
+	triggers := []models.PendingTrigger{}
+	for _, event := range events {
+		triggers = append(triggers, e.eventBus.Collect(gameState, event)...)
+	}
+
+	sort.SliceStable(triggers, func(i, j int) bool {
+		return triggers[i].PlayerID == gameState.ActivePlayer && triggers[j].PlayerID != gameState.ActivePlayer
+	})
+
+	gameState.PendingTriggers = append(gameState.PendingTriggers, triggers...)
+}
+
+// resolveTriggers 依序處理待處理的觸發效果
+// 同一玩家有多個同時觸發且尚未排序的效果時，先由該玩家選擇處理順序；有待決選擇時暫停，選擇解決後繼續
+func (e *gameEngine) resolveTriggers(gameState *models.GameState, result *ActionResult) {
+	for resolved := 0; len(gameState.PendingDecisions) == 0; resolved++ {
+		e.collectTriggers(gameState)
+		if len(gameState.PendingTriggers) == 0 {
+			return
+		}
+
+		if resolved >= maxTriggerResolutions {
+			logger.Error("Too many chained triggers, discarding the rest",
+				zap.Int("discarded", len(gameState.PendingTriggers)))
+			gameState.PendingTriggers = nil
+			return
+		}
+
+		if group := unorderedTriggerGroup(gameState.PendingTriggers); group > 1 {
+			e.requestTriggerOrderDecision(gameState, gameState.PendingTriggers[:group], result)
+			return
+		}
+
+		trigger := gameState.PendingTriggers[0]
+		gameState.PendingTriggers = gameState.PendingTriggers[1:]
+		e.applyTrigger(gameState, trigger, result)
+	}
+}
+
+// unorderedTriggerGroup 返回佇列開頭同一控制者且尚未排序的觸發效果數量
+func unorderedTriggerGroup(triggers []models.PendingTrigger) int {
+	if len(triggers) == 0 || triggers[0].Ordered {
+		return 0
+	}
+
+	count := 0
+	for _, trigger := range triggers {
+		if trigger.PlayerID != triggers[0].PlayerID || trigger.Ordered {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// applyTrigger 處理一個觸發效果
+// 觸發效果處理失敗時只記錄日誌，不影響原本的動作結果
+func (e *gameEngine) applyTrigger(gameState *models.GameState, trigger models.PendingTrigger, result *ActionResult) {
+	sourceID := trigger.SourceCard.ID
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:      "ABILITY_TRIGGERED",
+		Source:    &sourceID,
+		Target:    &trigger.PlayerID,
+		Data:      map[string]interface{}{"timing": trigger.Timing, "effect": trigger.Effect},
+		Timestamp: e.clock.Now(),
+	})
+
+	if err := e.effectManager.ApplyEffect(context.Background(), gameState, &trigger.Effect, &trigger.SourceCard, result); err != nil {
+		logger.Debug("Triggered ability not applied",
+			zap.Error(err),
+			zap.String("timing", trigger.Timing),
+			zap.String("card", trigger.SourceCard.Name))
+	}
+}
+
+// requestTriggerOrderDecision 建立觸發效果處理順序的選擇
+// 玩家以選擇的順序作為處理順序，超過期限時依觸發順序處理
+func (e *gameEngine) requestTriggerOrderDecision(gameState *models.GameState, triggers []models.PendingTrigger, result *ActionResult) {
+	options := make([]models.DecisionOption, 0, len(triggers))
+	defaults := make([]string, 0, len(triggers))
+	for _, trigger := range triggers {
+		cardID := trigger.SourceCard.ID
+		label := trigger.SourceCard.Name
+		if trigger.Effect.Description != "" {
+			label = fmt.Sprintf("%s：%s", trigger.SourceCard.Name, trigger.Effect.Description)
+		}
+		options = append(options, models.DecisionOption{ID: trigger.ID.String(), Label: label, CardID: &cardID})
+		defaults = append(defaults, trigger.ID.String())
+	}
+
+	e.requestDecision(gameState, models.PendingDecision{
+		Type:           models.DecisionTypeTriggerOrder,
+		PlayerID:       triggers[0].PlayerID,
+		Prompt:         fmt.Sprintf("有%d個效果同時觸發，請依處理順序選擇", len(triggers)),
+		Options:        options,
+		MinChoices:     len(triggers),
+		MaxChoices:     len(triggers),
+		DefaultChoices: defaults,
+	}, result)
+}
+
+// resolveTriggerOrderDecision 處理觸發效果處理順序的選擇
+// 依玩家選擇的順序重新排列佇列開頭的觸發效果並標記為已排序，之後由 resolveTriggers 繼續處理
+func resolveTriggerOrderDecision(e *gameEngine, gameState *models.GameState, decision *models.PendingDecision, choices []string, result *ActionResult) error {
+	group := len(choices)
+	if len(gameState.PendingTriggers) < group {
+		return fmt.Errorf("triggered abilities are no longer pending")
+	}
+
+	byID := make(map[string]models.PendingTrigger, group)
+	for _, trigger := range gameState.PendingTriggers[:group] {
+		byID[trigger.ID.String()] = trigger
+	}
+
+	ordered := make([]models.PendingTrigger, 0, group)
+	for _, choice := range choices {
+		trigger, exists := byID[choice]
+		if !exists {
+			return fmt.Errorf("triggered ability %s is no longer pending", choice)
+		}
+		trigger.Ordered = true
+		ordered = append(ordered, trigger)
+	}
+
+	copy(gameState.PendingTriggers, ordered)
+	return nil
+}
+
+// cardAbilityTriggers 卡片能力的時點訂閱者
+// 檢查雙方場上卡片（退場時包含退場的卡片）的能力，action.trigger 符合時點且 action.watch 符合事件範圍時觸發
+func cardAbilityTriggers(gameState *models.GameState, event models.TimingEvent) []models.PendingTrigger {
+	triggers := []models.PendingTrigger{}
+
+	for _, playerID := range effectPlayerOrder(gameState) {
+		player := gameState.Players[playerID]
+		for _, line := range [][]models.CardInPlay{player.Board.FrontLine, player.Board.EnergyLine} {
+			for _, card := range line {
+				triggers = append(triggers, abilityTriggers(gameState, card.Card, playerID, event)...)
+			}
+		}
+	}
+
+	// 退場的卡片已不在場上，以事件中的卡片資料處理自身的退場能力
+	if event.Timing == models.TimingOnRetire && event.Card != nil {
+		triggers = append(triggers, abilityTriggers(gameState, *event.Card, event.PlayerID, event)...)
+	}
+
+	return triggers
+}
+
+// abilityTriggers 檢查一張卡片回應事件的能力
+func abilityTriggers(gameState *models.GameState, card models.Card, controller uuid.UUID, event models.TimingEvent) []models.PendingTrigger {
+	triggers := []models.PendingTrigger{}
+	for _, ability := range card.Abilities {
+		if trigger, _ := ability.Action["trigger"].(string); trigger != event.Timing {
+			continue
+		}
+		if !abilityWatches(ability, card, controller, event) {
+			continue
+		}
+
+		triggers = append(triggers, models.PendingTrigger{
+			ID:         newID(gameState),
+			Timing:     event.Timing,
+			PlayerID:   controller,
+			SourceCard: card,
+			Effect:     bindAbilityTargets(gameState, ability, card, controller, event),
+		})
+	}
+	return triggers
+}
+
+// abilityWatches 判斷能力是否回應事件
+// 未指定 action.watch 時，與卡片有關的時點（登場、攻擊、防禦、退場）預設只回應自身，其他時點預設回應控制者
+func abilityWatches(ability models.CardEffect, card models.Card, controller uuid.UUID, event models.TimingEvent) bool {
+	watch, exists := ability.Action["watch"].(string)
+	if !exists {
+		switch event.Timing {
+		case models.TimingOnPlay, models.TimingOnAttack, models.TimingOnBlock, models.TimingOnRetire:
+			watch = models.WatchSelf
+		default:
+			watch = models.WatchController
+		}
+	}
+
+	switch watch {
+	case models.WatchSelf:
+		return event.Card != nil && event.Card.ID == card.ID
+	case models.WatchController:
+		return event.PlayerID == controller
+	case models.WatchOpponent:
+		return event.PlayerID != controller
+	case models.WatchAny:
+		return true
+	default:
+		return false
+	}
+}
+
+// bindAbilityTargets 代入能力的對象
+// action.target 可使用 self（自身）、event_card（事件的卡片）、controller（控制者）、opponent（對手）、event_player（事件的玩家）
+func bindAbilityTargets(gameState *models.GameState, ability models.CardEffect, card models.Card, controller uuid.UUID, event models.TimingEvent) models.CardEffect {
+	effect := ability
+	effect.Action = make(map[string]interface{}, len(ability.Action))
+	for key, value := range ability.Action {
+		effect.Action[key] = value
+	}
+
+	target, _ := effect.Action["target"].(string)
+	switch target {
+	case "self":
+		effect.Action["target"] = card.ID.String()
+	case "event_card":
+		if event.Card != nil {
+			effect.Action["target"] = event.Card.ID.String()
+		}
+	case "controller":
+		effect.Action["target"] = controller.String()
+	case "opponent":
+		for playerID := range gameState.Players {
+			if playerID != controller {
+				effect.Action["target"] = playerID.String()
+			}
+		}
+	case "event_player":
+		effect.Action["target"] = event.PlayerID.String()
+	}
+
+	return effect
+}
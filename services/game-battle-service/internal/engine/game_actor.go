@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// gameCommand 交由遊戲goroutine執行的操作
+type gameCommand struct {
+	run  func(actor *gameActor) error
+	done chan error
+}
+
+// gameActor 負責執行單一遊戲的所有操作
+// 每場遊戲有一個專屬的goroutine依序執行指令，同一場遊戲的操作不會同時執行，不同遊戲之間互不阻塞
+type gameActor struct {
+	gameID    uuid.UUID
+	gameState *models.GameState
+	commands  chan gameCommand
+	stopped   chan struct{}
+}
+
+// newGameActor 創建遊戲的執行者並啟動其goroutine
+func newGameActor(gameID uuid.UUID, gameState *models.GameState) *gameActor {
+	actor := &gameActor{
+		gameID:    gameID,
+		gameState: gameState,
+		commands:  make(chan gameCommand),
+		stopped:   make(chan struct{}),
+	}
+	go actor.loop()
+	return actor
+}
+
+// loop 依序執行收到的指令，直到執行者停止
+func (a *gameActor) loop() {
+	for {
+		select {
+		case command := <-a.commands:
+			command.done <- a.execute(command.run)
+		case <-a.stopped:
+			return
+		}
+	}
+}
+
+// stop 停止執行者的goroutine，之後送出的指令會返回遊戲不存在
+func (a *gameActor) stop() {
+	close(a.stopped)
+}
+
+// execute 執行一個指令
+// 指令發生panic時記錄錯誤並返回給呼叫者，避免整個服務因單一遊戲的錯誤而終止
+func (a *gameActor) execute(run func(actor *gameActor) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Game command panicked",
+				zap.String("game_id", a.gameID.String()),
+				zap.Any("panic", r))
+			err = fmt.Errorf("internal error while processing game: %v", r)
+		}
+	}()
+
+	return run(a)
+}
+
+// do 在遊戲的goroutine中執行操作並等待完成，返回操作的錯誤
+// 指令排入前 ctx 被取消時返回錯誤；指令開始執行後會等待執行完成，不會中途放棄
+func (a *gameActor) do(ctx context.Context, run func(gameState *models.GameState) error) error {
+	return a.send(ctx, func(actor *gameActor) error { return run(actor.gameState) })
+}
+
+// send 將指令排入遊戲的goroutine並等待完成
+func (a *gameActor) send(ctx context.Context, run func(actor *gameActor) error) error {
+	command := gameCommand{run: run, done: make(chan error, 1)}
+	select {
+	case a.commands <- command:
+	case <-a.stopped:
+		return fmt.Errorf("game not found")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return <-command.done
+}
+
+// gameRegistry 引擎記憶體中的遊戲登錄表
+// 以讀寫鎖保護遊戲ID與執行者的對應；遊戲狀態本身只由各遊戲的執行者存取
+type gameRegistry struct {
+	mu    sync.RWMutex
+	games map[uuid.UUID]*gameActor
+}
+
+// newGameRegistry 創建空的遊戲登錄表
+func newGameRegistry() *gameRegistry {
+	return &gameRegistry{games: make(map[uuid.UUID]*gameActor)}
+}
+
+// get 獲取遊戲的執行者
+func (r *gameRegistry) get(gameID uuid.UUID) (*gameActor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	actor, exists := r.games[gameID]
+	return actor, exists
+}
+
+// store 將遊戲狀態放入登錄表
+// 遊戲已存在時由原本的執行者替換遊戲狀態，確保替換不會與進行中的操作同時發生
+func (r *gameRegistry) store(ctx context.Context, gameID uuid.UUID, gameState *models.GameState) error {
+	r.mu.Lock()
+	actor, exists := r.games[gameID]
+	if !exists {
+		r.games[gameID] = newGameActor(gameID, gameState)
+	}
+	r.mu.Unlock()
+
+	if !exists {
+		return nil
+	}
+	return actor.send(ctx, func(actor *gameActor) error {
+		actor.gameState = gameState
+		return nil
+	})
+}
+
+// remove 從登錄表移除遊戲並停止其執行者，返回遊戲是否存在
+func (r *gameRegistry) remove(gameID uuid.UUID) bool {
+	r.mu.Lock()
+	actor, exists := r.games[gameID]
+	delete(r.games, gameID)
+	r.mu.Unlock()
+
+	if exists {
+		actor.stop()
+	}
+	return exists
+}
+
+// list 返回目前所有遊戲的執行者
+func (r *gameRegistry) list() []*gameActor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	actors := make([]*gameActor, 0, len(r.games))
+	for _, actor := range r.games {
+		actors = append(actors, actor)
+	}
+	return actors
+}
+
+// count 返回記憶體中的遊戲數量
+func (r *gameRegistry) count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.games)
+}
+
+// withGame 在遊戲的執行者中執行操作，遊戲不存在時返回錯誤
+func (e *gameEngine) withGame(ctx context.Context, gameID uuid.UUID, run func(gameState *models.GameState) error) error {
+	actor, exists := e.games.get(gameID)
+	if !exists {
+		return fmt.Errorf("game not found")
+	}
+	return actor.do(ctx, run)
+}
+
+// copyGameState 完整複製遊戲狀態
+// 引擎對外返回的遊戲狀態都是副本，呼叫者讀取或序列化時不會與遊戲的執行者同時存取
+func copyGameState(gameState *models.GameState) (*models.GameState, error) {
+	data, err := json.Marshal(gameState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy game state: %w", err)
+	}
+
+	copied := &models.GameState{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, fmt.Errorf("failed to copy game state: %w", err)
+	}
+	return copied, nil
+}
@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// newTestDeck 建立測試用的50張角色卡卡組
+func newTestDeck() []models.Card {
+	deck := make([]models.Card, 0, 50)
+	for i := 0; i < 50; i++ {
+		bp := 3000 + (i%5)*1000
+		deck = append(deck, models.Card{
+			ID:            uuid.New(),
+			Name:          "Test Character",
+			CardType:      models.CardTypeCharacter,
+			Color:         "RED",
+			BP:            &bp,
+			APCost:        1,
+			EnergyCost:    json.RawMessage(`{}`),
+			EnergyProduce: json.RawMessage(`{"red":1}`),
+		})
+	}
+	return deck
+}
+
+// newTestGame 建立已完成調度的遊戲
+// 返回引擎、遊戲ID與兩位玩家，Player1為先攻
+func newTestGame(t *testing.T) (GameEngine, uuid.UUID, []uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+
+	clock := &ManualClock{}
+	clock.Set(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := NewGameEngine(rand.New(rand.NewPCG(1, 2)), clock)
+
+	gameID := uuid.New()
+	players := []uuid.UUID{uuid.New(), uuid.New()}
+	_, err := e.InitializeGame(ctx, &InitGameRequest{
+		GameID:  gameID,
+		Player1: &PlayerSetup{UserID: players[0], Deck: newTestDeck()},
+		Player2: &PlayerSetup{UserID: players[1], Deck: newTestDeck()},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize game: %v", err)
+	}
+
+	for _, playerID := range players {
+		if _, err := e.PerformMulligan(ctx, &MulliganRequest{GameID: gameID, PlayerID: playerID}); err != nil {
+			t.Fatalf("failed to perform mulligan: %v", err)
+		}
+	}
+	return e, gameID, players
+}
+
+// endPhaseAction 建立結束階段的動作，只有行動玩家送出時會成功
+func endPhaseAction(gameID uuid.UUID, playerID uuid.UUID) *models.GameAction {
+	return &models.GameAction{
+		ID:         uuid.New(),
+		GameID:     gameID,
+		PlayerID:   playerID,
+		ActionType: models.ActionTypeEndPhase,
+		ActionData: json.RawMessage(`{}`),
+	}
+}
+
+// TestConcurrentActionsAreSerialized 同時對同一場遊戲送出動作
+// 每個成功的動作都應使版本號加一，不會有動作被同時執行而遺失
+func TestConcurrentActionsAreSerialized(t *testing.T) {
+	ctx := context.Background()
+	e, gameID, players := newTestGame(t)
+
+	initial, err := e.GetGameState(ctx, gameID)
+	if err != nil {
+		t.Fatalf("failed to get game state: %v", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int64
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(playerID uuid.UUID) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				result, err := e.ProcessAction(ctx, gameID, endPhaseAction(gameID, playerID))
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if result.Success {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}
+		}(players[i%2])
+	}
+	wg.Wait()
+
+	final, err := e.GetGameState(ctx, gameID)
+	if err != nil {
+		t.Fatalf("failed to get game state: %v", err)
+	}
+	if succeeded == 0 {
+		t.Fatal("no action succeeded")
+	}
+	if final.Version != initial.Version+succeeded {
+		t.Errorf("version = %d, want %d after %d successful actions", final.Version, initial.Version+succeeded, succeeded)
+	}
+}
+
+// TestConcurrentReadsLoadsAndActions 同時處理動作、讀取與重新載入同一場遊戲
+// 以 go test -race 執行，確認引擎返回與接收的狀態都是副本，不會與遊戲的執行者同時存取
+func TestConcurrentReadsLoadsAndActions(t *testing.T) {
+	ctx := context.Background()
+	e, gameID, players := newTestGame(t)
+
+	var wg sync.WaitGroup
+	for _, playerID := range players {
+		wg.Add(1)
+		go func(playerID uuid.UUID) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				result, err := e.ProcessAction(ctx, gameID, endPhaseAction(gameID, playerID))
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				// 返回的狀態可以在執行者繼續處理其他動作時讀取
+				if result.Success && result.GameState.Players[playerID] == nil {
+					t.Errorf("player missing from result state")
+				}
+			}
+		}(playerID)
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				gameState, err := e.GetGameState(ctx, gameID)
+				if err != nil {
+					t.Errorf("failed to get game state: %v", err)
+					return
+				}
+				// 修改副本不應影響引擎中的遊戲
+				gameState.Turn = -1
+				for _, player := range gameState.Players {
+					player.Hand = nil
+				}
+				if _, err := json.Marshal(gameState); err != nil {
+					t.Errorf("failed to serialize game state: %v", err)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				gameState, err := e.GetGameState(ctx, gameID)
+				if err != nil {
+					t.Errorf("failed to get game state: %v", err)
+					return
+				}
+				if err := e.LoadGameState(ctx, gameID, gameState); err != nil {
+					t.Errorf("failed to load game state: %v", err)
+					return
+				}
+				// 載入後呼叫者仍可使用原本的狀態
+				gameState.Phase = models.EndPhase
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			e.ExpiredClocks(ctx)
+		}
+	}()
+	wg.Wait()
+
+	final, err := e.GetGameState(ctx, gameID)
+	if err != nil {
+		t.Fatalf("failed to get game state: %v", err)
+	}
+	if final.Turn < 1 {
+		t.Errorf("turn = %d, a copy was written back into the engine", final.Turn)
+	}
+	if len(final.Players) != 2 {
+		t.Errorf("players = %d, want 2", len(final.Players))
+	}
+}
+
+// TestUnloadDuringActions 在處理動作時卸載遊戲
+// 卸載後的操作應返回遊戲不存在，而不是卡住或存取已釋放的狀態
+func TestUnloadDuringActions(t *testing.T) {
+	ctx := context.Background()
+	e, gameID, players := newTestGame(t)
+
+	var wg sync.WaitGroup
+	for _, playerID := range players {
+		wg.Add(1)
+		go func(playerID uuid.UUID) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := e.ProcessAction(ctx, gameID, endPhaseAction(gameID, playerID)); err != nil && err.Error() != "game not found" {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if _, err := e.GetGameState(ctx, gameID); err != nil && err.Error() != "game not found" {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}(playerID)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := e.UnloadGame(ctx, gameID); err != nil {
+			t.Errorf("failed to unload game: %v", err)
+		}
+	}()
+	wg.Wait()
+
+	if _, err := e.GetGameState(ctx, gameID); err == nil || err.Error() != "game not found" {
+		t.Errorf("GetGameState after unload: err = %v, want game not found", err)
+	}
+	if len(e.ExpiredClocks(ctx)) != 0 {
+		t.Error("unloaded game still has a running clock")
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
 	"time"
 
 	"ua/shared/logger"
@@ -13,30 +16,42 @@ import (
 	"go.uber.org/zap"
 )
 
+// GameEngine 遊戲引擎
+// 以遊戲ID操作的方法可以同時呼叫：同一場遊戲的操作在該遊戲專屬的goroutine中依序執行，返回的遊戲狀態都是副本。
+// 接受 gameState 參數的方法直接使用傳入的狀態，呼叫者需確保該狀態不會同時被修改
 type GameEngine interface {
 	InitializeGame(ctx context.Context, req *InitGameRequest) (*models.GameState, error)
-	PerformMulligan(ctx context.Context, req *MulliganRequest) error
+	PerformMulligan(ctx context.Context, req *MulliganRequest) (*models.GameState, error)
 	ProcessAction(ctx context.Context, gameID uuid.UUID, action *models.GameAction) (*ActionResult, error)
 	GetGameState(ctx context.Context, gameID uuid.UUID) (*models.GameState, error)
 	LoadGameState(ctx context.Context, gameID uuid.UUID, gameState *models.GameState) error
+	UnloadGame(ctx context.Context, gameID uuid.UUID) error
 	ValidateAction(ctx context.Context, gameState *models.GameState, action *models.GameAction) error
 	AdvancePhase(ctx context.Context, gameID uuid.UUID) (*models.GameState, error)
 	CheckWinCondition(ctx context.Context, gameState *models.GameState) (*WinCondition, error)
-	ApplyCardEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error
+	ApplyCardEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error
 	CalculateDamage(ctx context.Context, attacker, defender *models.CardInPlay, gameState *models.GameState) (int, error)
+	LegalActions(gameState *models.GameState, playerID uuid.UUID) []LegalAction
+	SimulateAction(gameState *models.GameState, playerID uuid.UUID, action LegalAction) (*models.GameState, error)
+	ExpiredClocks(ctx context.Context) []ExpiredClock
+	ExpiredDecisions(ctx context.Context) []ExpiredDecision
 }
 
 type InitGameRequest struct {
 	GameID  uuid.UUID    `json:"game_id"`
 	Player1 *PlayerSetup `json:"player1"`
 	Player2 *PlayerSetup `json:"player2"`
+	Seed    *int64       `json:"seed,omitempty"` // 指定遊戲的隨機種子，未指定時由引擎產生
+	// 遊戲的時間限制，未指定時不限時
+	TimeControl *models.TimeControl `json:"time_control,omitempty"`
 }
 
 // MulliganRequest 調度手牌請求
 type MulliganRequest struct {
-	GameID   uuid.UUID `json:"game_id"`
-	PlayerID uuid.UUID `json:"player_id"`
-	Mulligan bool      `json:"mulligan"` // true=調度，false=不調度
+	GameID      uuid.UUID `json:"game_id"`
+	PlayerID    uuid.UUID `json:"player_id"`
+	Mulligan    bool      `json:"mulligan"`     // true=調度，false=不調度
+	PerformedAt time.Time `json:"performed_at"` // 由引擎以其時鐘設定，雙方完成調度時計時也從此時開始
 }
 
 // SetupLifeAreaRequest 設置生命區請求（在所有調度完成後）
@@ -44,6 +59,9 @@ type SetupLifeAreaRequest struct {
 	GameID uuid.UUID `json:"game_id"`
 }
 
+// lineCapacity 前線與能源線各自最多可放置的卡片數量
+const lineCapacity = 4
+
 type PlayerSetup struct {
 	UserID uuid.UUID     `json:"user_id"`
 	Deck   []models.Card `json:"deck"`
@@ -79,22 +97,46 @@ type WinCondition struct {
 	HasWinner bool       `json:"has_winner"`
 	Winner    *uuid.UUID `json:"winner,omitempty"`
 	Reason    string     `json:"reason"`
+	EndReason string     `json:"end_reason,omitempty"` // 遊戲結束的原因，為 models.EndReason* 之一
 }
 
 type gameEngine struct {
-	gameStates    map[uuid.UUID]*models.GameState
-	effectManager EffectManager
-	turnManager   TurnManager
+	games             *gameRegistry
+	effectManager     EffectManager
+	turnManager       TurnManager
+	decisionResolvers map[string]DecisionResolver
+	keywordHandlers   map[string]KeywordHandler
+	eventBus          *EventBus
+	rng               *rand.Rand
+	rngMu             sync.Mutex
+	clock             Clock
 }
 
 // NewGameEngine 創建新的遊戲引擎實例
-// 初始化遊戲狀態映射、效果管理器和回合管理器
-func NewGameEngine() GameEngine {
-	return &gameEngine{
-		gameStates:    make(map[uuid.UUID]*models.GameState),
-		effectManager: NewEffectManager(),
-		turnManager:   NewTurnManager(),
-	}
+// rng 用於產生每場遊戲的隨機種子，clock 提供引擎使用的時間；傳入nil時使用隨機種子與系統時鐘
+func NewGameEngine(rng *rand.Rand, clock Clock) GameEngine {
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	e := &gameEngine{
+		games:             newGameRegistry(),
+		turnManager:       NewTurnManager(),
+		decisionResolvers: make(map[string]DecisionResolver),
+		keywordHandlers:   make(map[string]KeywordHandler),
+		eventBus:          NewEventBus(),
+		rng:               rng,
+		clock:             clock,
+	}
+	e.effectManager = NewEffectManager(e.dealDamageToPlayer, clock)
+
+	e.registerDecisionResolvers()
+	e.registerKeywordHandlers()
+	e.registerTriggerSubscribers()
+	return e
 }
 
 // InitializeGame 初始化新遊戲
@@ -127,7 +169,7 @@ func (e *gameEngine) InitializeGame(ctx context.Context, req *InitGameRequest) (
 		MaxAP:    3, // 初始最大 AP
 		Energy:   make(map[string]int),
 		Hand:     []models.Card{},
-		Deck:     req.Player1.Deck,
+		Deck:     append([]models.Card(nil), req.Player1.Deck...), // 複製卡組，洗牌不影響呼叫者的卡組
 		Board: models.Board{
 			FrontLine:   make([]models.CardInPlay, 0, 4), // 前線：最多4張
 			EnergyLine:  make([]models.CardInPlay, 0, 4), // 能源線：最多4張
@@ -148,7 +190,7 @@ func (e *gameEngine) InitializeGame(ctx context.Context, req *InitGameRequest) (
 		MaxAP:    3, // 初始最大 AP
 		Energy:   make(map[string]int),
 		Hand:     []models.Card{},
-		Deck:     req.Player2.Deck,
+		Deck:     append([]models.Card(nil), req.Player2.Deck...),
 		Board: models.Board{
 			FrontLine:   make([]models.CardInPlay, 0, 4), // 前線：最多4張
 			EnergyLine:  make([]models.CardInPlay, 0, 4), // 能源線：最多4張
@@ -162,18 +204,9 @@ func (e *gameEngine) InitializeGame(ctx context.Context, req *InitGameRequest) (
 		ExtraDrawUsed: false,
 	}
 
-	// 1. 洗牌
-	e.shuffleDeck(player1.Deck)
-	e.shuffleDeck(player2.Deck)
-
-	// 2. 抽取初始手牌（7張）
-	for i := 0; i < 7; i++ {
-		e.drawCard(player1)
-		e.drawCard(player2)
-	}
-
 	// 初始化遊戲狀態
 	gameState := &models.GameState{
+		Seed:         e.newSeed(req.Seed),
 		Turn:         1,
 		Phase:        models.StartPhase,
 		ActivePlayer: req.Player1.UserID,
@@ -185,25 +218,65 @@ func (e *gameEngine) InitializeGame(ctx context.Context, req *InitGameRequest) (
 		ActionLog:         []models.GameAction{},
 		MulliganCompleted: make(map[uuid.UUID]bool),
 		LifeAreaSetup:     false,
+		PendingDecisions:  []models.PendingDecision{},
+	}
+
+	if req.TimeControl != nil {
+		timeControl := *req.TimeControl
+		gameState.TimeControl = &timeControl
+		gameState.Clocks = newClocks(&timeControl, req.Player1.UserID, req.Player2.UserID)
+	}
+
+	// 1. 洗牌
+	e.shuffleDeck(gameState, player1.Deck)
+	e.shuffleDeck(gameState, player2.Deck)
+
+	// 2. 抽取初始手牌（7張）
+	for i := 0; i < 7; i++ {
+		e.drawCard(player1)
+		e.drawCard(player2)
 	}
 
-	e.gameStates[req.GameID] = gameState
+	// 返回副本，儲存的遊戲狀態之後只由遊戲的執行者存取
+	initialState, err := copyGameState(gameState)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.games.store(ctx, req.GameID, gameState); err != nil {
+		return nil, err
+	}
 
 	logger.Info("Game initialized",
 		zap.String("game_id", req.GameID.String()),
 		zap.String("player1", req.Player1.UserID.String()),
-		zap.String("player2", req.Player2.UserID.String()))
+		zap.String("player2", req.Player2.UserID.String()),
+		zap.Int64("seed", gameState.Seed))
 
-	return gameState, nil
+	return initialState, nil
 }
 
 // PerformMulligan 執行調度手牌
-// 每個玩家可以獨立決定是否調度，無需等待對方，當雙方都完成決定後自動設置生命區
-func (e *gameEngine) PerformMulligan(ctx context.Context, req *MulliganRequest) error {
-	gameState, exists := e.gameStates[req.GameID]
-	if !exists {
-		return fmt.Errorf("game not found")
+// 每個玩家可以獨立決定是否調度，無需等待對方，當雙方都完成決定後自動設置生命區，返回調度後遊戲狀態的副本
+func (e *gameEngine) PerformMulligan(ctx context.Context, req *MulliganRequest) (*models.GameState, error) {
+	var snapshot *models.GameState
+	err := e.withGame(ctx, req.GameID, func(gameState *models.GameState) error {
+		if err := e.performMulligan(ctx, gameState, req); err != nil {
+			return err
+		}
+		var err error
+		snapshot, err = copyGameState(gameState)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return snapshot, nil
+}
+
+// performMulligan 在遊戲狀態上執行調度手牌
+func (e *gameEngine) performMulligan(ctx context.Context, gameState *models.GameState, req *MulliganRequest) error {
+	// 調度的時間在驗證前設定，記錄到動作記錄後重播時可重現
+	req.PerformedAt = e.clock.Now()
 
 	player, exists := gameState.Players[req.PlayerID]
 	if !exists {
@@ -239,7 +312,7 @@ func (e *gameEngine) PerformMulligan(ctx context.Context, req *MulliganRequest)
 
 		// 將舊手牌洗回卡組
 		player.Deck = append(player.Deck, oldHand...)
-		e.shuffleDeck(player.Deck)
+		e.shuffleDeck(gameState, player.Deck)
 
 		logger.Debug("Player performed mulligan",
 			zap.String("player", req.PlayerID.String()),
@@ -264,24 +337,20 @@ func (e *gameEngine) PerformMulligan(ctx context.Context, req *MulliganRequest)
 		}
 
 		if allCompleted {
-			err := e.autoSetupLifeArea(ctx, req.GameID)
+			err := e.autoSetupLifeArea(ctx, req.GameID, gameState, req.PerformedAt)
 			if err != nil {
 				return fmt.Errorf("failed to setup life area after mulligan: %v", err)
 			}
 		}
 	}
 
+	gameState.Version++
 	return nil
 }
 
 // autoSetupLifeArea 自動設置生命區並啟動遊戲（內部函數）
-// 在所有玩家完成調度後自動調用，設置生命區並開始第一回合
-func (e *gameEngine) autoSetupLifeArea(ctx context.Context, gameID uuid.UUID) error {
-	gameState, exists := e.gameStates[gameID]
-	if !exists {
-		return fmt.Errorf("game not found")
-	}
-
+// 在所有玩家完成調度後自動調用，設置生命區並開始第一回合，計時從 now 開始
+func (e *gameEngine) autoSetupLifeArea(ctx context.Context, gameID uuid.UUID, gameState *models.GameState, now time.Time) error {
 	if gameState.LifeAreaSetup {
 		return fmt.Errorf("life area already set up")
 	}
@@ -306,6 +375,7 @@ func (e *gameEngine) autoSetupLifeArea(ctx context.Context, gameID uuid.UUID) er
 	if err != nil {
 		return fmt.Errorf("failed to start first turn: %v", err)
 	}
+	startClock(gameState, now)
 
 	logger.Info("Life areas set up and game started",
 		zap.String("game_id", gameID.String()),
@@ -327,6 +397,7 @@ func (e *gameEngine) startFirstTurn(ctx context.Context, gameState *models.GameS
 	if err != nil {
 		return fmt.Errorf("failed to process turn start: %v", err)
 	}
+	emitTiming(gameState, models.TimingStartOfTurn, gameState.ActivePlayer, nil, map[string]interface{}{"turn": gameState.Turn})
 
 	logger.Info("First turn started",
 		zap.String("active_player", gameState.ActivePlayer.String()),
@@ -342,21 +413,43 @@ func (e *gameEngine) ProcessAction(ctx context.Context, gameID uuid.UUID, action
 	logger.Debug("ProcessAction called",
 		zap.String("game_id", gameID.String()),
 		zap.String("action_type", action.ActionType),
-		zap.Int("total_games_in_memory", len(e.gameStates)))
+		zap.Int("total_games_in_memory", e.games.count()))
 	
-	gameState, exists := e.gameStates[gameID]
+	actor, exists := e.games.get(gameID)
 	if !exists {
 		logger.Debug("Game not found in engine memory",
 			zap.String("game_id", gameID.String()),
-			zap.Int("total_games_in_memory", len(e.gameStates)))
+			zap.Int("total_games_in_memory", e.games.count()))
 		return nil, fmt.Errorf("game not found")
 	}
 
+	var result *ActionResult
+	err := actor.do(ctx, func(gameState *models.GameState) error {
+		var err error
+		result, err = e.processAction(ctx, gameState, action)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// processAction 在遊戲狀態上處理動作
+// 於遊戲的執行者中呼叫，返回結果中的遊戲狀態為處理後的副本
+func (e *gameEngine) processAction(ctx context.Context, gameState *models.GameState, action *models.GameAction) (*ActionResult, error) {
+	// 動作的時間戳在驗證前設定，計時與超時判斷都以此為準，重播時可重現
+	action.Timestamp = e.clock.Now()
+
 	if err := e.ValidateAction(ctx, gameState, action); err != nil {
+		snapshot, copyErr := copyGameState(gameState)
+		if copyErr != nil {
+			return nil, copyErr
+		}
 		return &ActionResult{
 			Success:   false,
 			Error:     err.Error(),
-			GameState: gameState,
+			GameState: snapshot,
 		}, nil
 	}
 
@@ -367,6 +460,45 @@ func (e *gameEngine) ProcessAction(ctx context.Context, gameID uuid.UUID, action
 		EventsTriggered: []GameEvent{},
 	}
 
+	// 記錄動作發生時的回合與階段
+	action.Turn = gameState.Turn
+	action.Phase = gameState.Phase
+
+	e.applyAction(gameState, action, result)
+
+	action.IsValid = result.Success
+	if !result.Success {
+		action.ErrorMsg = result.Error
+	} else {
+		runClock(gameState, action.Turn, action.Timestamp)
+		// 只有成功的動作會被儲存，版本隨之遞增並作為動作的序號
+		gameState.Version++
+		action.SequenceNumber = gameState.Version
+	}
+	gameState.ActionLog = append(gameState.ActionLog, *action)
+
+	winCondition, _ := e.CheckWinCondition(ctx, gameState)
+	if winCondition.HasWinner {
+		stopClock(gameState)
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "GAME_ENDED",
+			Data:      map[string]interface{}{"winner": winCondition.Winner, "reason": winCondition.Reason},
+			Timestamp: e.clock.Now(),
+		})
+	}
+
+	snapshot, err := copyGameState(gameState)
+	if err != nil {
+		return nil, err
+	}
+	result.GameState = snapshot
+
+	return result, nil
+}
+
+// applyAction 執行已通過驗證的動作
+// 依動作類型交由對應的處理函數，並處理動作引發的觸發效果
+func (e *gameEngine) applyAction(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
 	switch action.ActionType {
 	case models.ActionTypeDrawCard:
 		e.processDrawCard(gameState, action, result)
@@ -376,6 +508,8 @@ func (e *gameEngine) ProcessAction(ctx context.Context, gameID uuid.UUID, action
 		e.processPlayCard(gameState, action, result)
 	case models.ActionTypeAttack:
 		e.processAttack(gameState, action, result)
+	case models.ActionTypeBlock:
+		e.processBlock(gameState, action, result)
 	case models.ActionTypeMoveCharacter:
 		e.processMoveCharacter(gameState, action, result)
 	case models.ActionTypeEndPhase:
@@ -384,63 +518,105 @@ func (e *gameEngine) ProcessAction(ctx context.Context, gameID uuid.UUID, action
 		e.processEndTurn(gameState, action, result)
 	case models.ActionTypeSurrender:
 		e.processSurrender(gameState, action, result)
+	case models.ActionTypeResolveDecision:
+		e.processResolveDecision(gameState, action, result)
+	case models.ActionTypeTimeout:
+		e.processTimeout(gameState, action, result)
+	case models.ActionTypeConnectionLost:
+		e.processConnectionLost(gameState, action, result)
 	default:
 		result.Success = false
 		result.Error = "unknown action type: " + action.ActionType
 	}
 
-	action.Timestamp = time.Now()
-	action.IsValid = result.Success
-	if !result.Success {
-		action.ErrorMsg = result.Error
-	}
-	gameState.ActionLog = append(gameState.ActionLog, *action)
-
-	winCondition, _ := e.CheckWinCondition(ctx, gameState)
-	if winCondition.HasWinner {
-		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
-			Type:      "GAME_ENDED",
-			Data:      map[string]interface{}{"winner": winCondition.Winner, "reason": winCondition.Reason},
-			Timestamp: time.Now(),
-		})
-	}
-
-	return result, nil
+	e.resolveTriggers(gameState, result)
+	refreshEnergy(gameState)
 }
 
 // GetGameState 獲取指定遊戲的當前狀態
-// 根據遊戲ID返回對應的遊戲狀態，若遊戲不存在則返回錯誤
+// 根據遊戲ID返回遊戲狀態的副本，若遊戲不存在則返回錯誤
 func (e *gameEngine) GetGameState(ctx context.Context, gameID uuid.UUID) (*models.GameState, error) {
-	gameState, exists := e.gameStates[gameID]
-	if !exists {
-		return nil, fmt.Errorf("game not found")
+	var snapshot *models.GameState
+	err := e.withGame(ctx, gameID, func(gameState *models.GameState) error {
+		var err error
+		snapshot, err = copyGameState(gameState)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	return gameState, nil
+	return snapshot, nil
 }
 
 // LoadGameState 將遊戲狀態載入到引擎記憶體中
-// 用於從資料庫恢復遊戲狀態到引擎記憶體
+// 用於從資料庫恢復遊戲狀態到引擎記憶體；引擎儲存傳入狀態的副本，呼叫者之後仍可自由使用原本的狀態
 func (e *gameEngine) LoadGameState(ctx context.Context, gameID uuid.UUID, gameState *models.GameState) error {
 	if gameState == nil {
 		return fmt.Errorf("game state cannot be nil")
 	}
 
-	// Store the game state in engine memory
-	e.gameStates[gameID] = gameState
+	loaded, err := copyGameState(gameState)
+	if err != nil {
+		return err
+	}
+	if err := e.games.store(ctx, gameID, loaded); err != nil {
+		return err
+	}
 
 	logger.Info("Game state loaded into engine memory",
 		zap.String("game_id", gameID.String()),
 		zap.Int("turn", gameState.Turn),
 		zap.String("phase", gameState.Phase.String()),
 		zap.String("active_player", gameState.ActivePlayer.String()),
-		zap.Int("total_games_in_memory", len(e.gameStates)))
+		zap.Int("total_games_in_memory", e.games.count()))
 
 	return nil
 }
 
+// UnloadGame 從引擎記憶體移除遊戲
+// 停止遊戲的執行者並釋放遊戲狀態，之後需要時可再以 LoadGameState 載入
+func (e *gameEngine) UnloadGame(ctx context.Context, gameID uuid.UUID) error {
+	if !e.games.remove(gameID) {
+		return fmt.Errorf("game not found")
+	}
+
+	logger.Debug("Game unloaded from engine memory",
+		zap.String("game_id", gameID.String()),
+		zap.Int("total_games_in_memory", e.games.count()))
+	return nil
+}
+
 // ValidateAction 驗證遊戲動作是否合法
 // 檢查是否為當前玩家回合、玩家是否存在、動作類型是否有效
 func (e *gameEngine) ValidateAction(ctx context.Context, gameState *models.GameState, action *models.GameAction) error {
+	// 勝負已分的遊戲不再接受任何動作
+	if winCondition, err := e.CheckWinCondition(ctx, gameState); err == nil && winCondition.HasWinner {
+		return fmt.Errorf("game is over")
+	}
+
+	// 超時由伺服器在計時玩家的時間用完時送出，待決選擇與防禦窗口期間也會接受
+	if action.ActionType == models.ActionTypeTimeout {
+		return e.validateTimeout(gameState, action)
+	}
+
+	// 斷線判負由伺服器在重新連線期限過後送出，與投降一樣隨時接受
+	if action.ActionType == models.ActionTypeConnectionLost {
+		if gameState.Players[action.PlayerID] == nil {
+			return fmt.Errorf("player not found")
+		}
+		return nil
+	}
+
+	// 有待決選擇時，只接受做出選擇的玩家回應與投降
+	if len(gameState.PendingDecisions) > 0 {
+		return e.validateDuringPendingDecision(gameState, action)
+	}
+
+	// 攻擊等待防禦宣告時，只接受防禦方的防禦宣告與投降
+	if gameState.PendingAttack != nil {
+		return e.validateDuringPendingAttack(gameState, action)
+	}
+
 	if action.PlayerID != gameState.ActivePlayer {
 		return fmt.Errorf("not your turn")
 	}
@@ -459,12 +635,16 @@ func (e *gameEngine) ValidateAction(ctx context.Context, gameState *models.GameS
 		return e.validatePlayCard(gameState, action)
 	case models.ActionTypeAttack:
 		return e.validateAttack(gameState, action)
+	case models.ActionTypeBlock:
+		return fmt.Errorf("no attack to block")
 	case models.ActionTypeMoveCharacter:
 		return e.validateMoveCharacter(gameState, action)
 	case models.ActionTypeEndPhase, models.ActionTypeEndTurn:
 		return nil
 	case models.ActionTypeSurrender:
 		return nil
+	case models.ActionTypeResolveDecision:
+		return fmt.Errorf("no pending decision")
 	default:
 		return fmt.Errorf("invalid action type")
 	}
@@ -473,11 +653,24 @@ func (e *gameEngine) ValidateAction(ctx context.Context, gameState *models.GameS
 // AdvancePhase 推進遊戲階段
 // 將當前階段推進到下一個階段，如果是結束階段則推進到下一回合
 func (e *gameEngine) AdvancePhase(ctx context.Context, gameID uuid.UUID) (*models.GameState, error) {
-	gameState, exists := e.gameStates[gameID]
-	if !exists {
-		return nil, fmt.Errorf("game not found")
+	var snapshot *models.GameState
+	err := e.withGame(ctx, gameID, func(gameState *models.GameState) error {
+		advanced, err := e.advancePhase(ctx, gameState)
+		if err != nil {
+			return err
+		}
+		advanced.Version++
+		snapshot, err = copyGameState(advanced)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return snapshot, nil
+}
 
+// advancePhase 推進指定遊戲狀態的階段
+func (e *gameEngine) advancePhase(ctx context.Context, gameState *models.GameState) (*models.GameState, error) {
 	switch gameState.Phase {
 	case models.StartPhase:
 		gameState.Phase = models.MovePhase
@@ -488,15 +681,55 @@ func (e *gameEngine) AdvancePhase(ctx context.Context, gameID uuid.UUID) (*model
 	case models.AttackPhase:
 		gameState.Phase = models.EndPhase
 	case models.EndPhase:
-		return e.advanceTurn(gameState), nil
+		emitTiming(gameState, models.TimingEndOfTurn, gameState.ActivePlayer, nil, map[string]interface{}{"turn": gameState.Turn})
+		if err := e.turnManager.ProcessPhaseStart(ctx, gameState, models.EndPhase); err != nil {
+			return nil, err
+		}
+		gameState = e.advanceTurn(gameState)
 	}
 
+	refreshEnergy(gameState)
 	return gameState, nil
 }
 
 // CheckWinCondition 檢查遊戲勝負條件
 // 根據Union Arena規則檢查兩個勝利條件：1)對手生命區歸零 2)對手卡組耗盡且無法抽卡
 func (e *gameEngine) CheckWinCondition(ctx context.Context, gameState *models.GameState) (*WinCondition, error) {
+	// 生命區設置前遊戲尚未開始
+	if !gameState.LifeAreaSetup {
+		return &WinCondition{HasWinner: false}, nil
+	}
+
+	// 有玩家投降時對手獲勝
+	if gameState.SurrenderedBy != nil {
+		return &WinCondition{
+			HasWinner: true,
+			Winner:    e.getOpponentID(gameState, *gameState.SurrenderedBy),
+			Reason:    "opponent surrendered",
+			EndReason: models.EndReasonSurrender,
+		}, nil
+	}
+
+	// 有玩家超時次數達到上限時對手獲勝
+	if gameState.TimedOut != nil {
+		return &WinCondition{
+			HasWinner: true,
+			Winner:    e.getOpponentID(gameState, *gameState.TimedOut),
+			Reason:    "opponent timed out",
+			EndReason: models.EndReasonTimeout,
+		}, nil
+	}
+
+	// 有玩家斷線未在期限內重新連線時對手獲勝
+	if gameState.ConnectionLost != nil {
+		return &WinCondition{
+			HasWinner: true,
+			Winner:    e.getOpponentID(gameState, *gameState.ConnectionLost),
+			Reason:    "opponent connection lost",
+			EndReason: models.EndReasonConnectionLost,
+		}, nil
+	}
+
 	for playerID, player := range gameState.Players {
 		opponentID := e.getOpponentID(gameState, playerID)
 
@@ -506,6 +739,7 @@ func (e *gameEngine) CheckWinCondition(ctx context.Context, gameState *models.Ga
 				HasWinner: true,
 				Winner:    opponentID,
 				Reason:    "opponent life area is empty",
+				EndReason: models.EndReasonNormalWin,
 			}, nil
 		}
 
@@ -518,6 +752,7 @@ func (e *gameEngine) CheckWinCondition(ctx context.Context, gameState *models.Ga
 					HasWinner: true,
 					Winner:    opponentID,
 					Reason:    "opponent cannot draw card in start phase",
+					EndReason: models.EndReasonDeckOut,
 				}, nil
 			}
 		}
@@ -527,9 +762,9 @@ func (e *gameEngine) CheckWinCondition(ctx context.Context, gameState *models.Ga
 }
 
 // ApplyCardEffect 應用卡牌效果
-// 委託給效果管理器來處理具體的效果應用
-func (e *gameEngine) ApplyCardEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card) error {
-	return e.effectManager.ApplyEffect(ctx, gameState, effect, sourceCard)
+// 委託給效果管理器來處理具體的效果應用，效果結果記錄到 result 中
+func (e *gameEngine) ApplyCardEffect(ctx context.Context, gameState *models.GameState, effect *models.CardEffect, sourceCard *models.Card, result *ActionResult) error {
+	return e.effectManager.ApplyEffect(ctx, gameState, effect, sourceCard, result)
 }
 
 // CalculateDamage 計算戰鬥傷害
@@ -539,26 +774,7 @@ func (e *gameEngine) CalculateDamage(ctx context.Context, attacker, defender *mo
 		return 0, fmt.Errorf("cannot calculate damage for non-character cards")
 	}
 
-	baseDamage := *attacker.Card.BP
-
-	for _, modifier := range attacker.Modifiers {
-		if modifier.Type == "bp_boost" {
-			if boost, ok := modifier.Value.(int); ok {
-				baseDamage += boost
-			}
-		}
-	}
-
-	defense := *defender.Card.BP
-	for _, modifier := range defender.Modifiers {
-		if modifier.Type == "bp_boost" {
-			if boost, ok := modifier.Value.(int); ok {
-				defense += boost
-			}
-		}
-	}
-
-	damage := baseDamage - defense
+	damage := EffectiveBP(attacker, gameState) - EffectiveBP(defender, gameState)
 	if damage < 0 {
 		damage = 0
 	}
@@ -567,7 +783,7 @@ func (e *gameEngine) CalculateDamage(ctx context.Context, attacker, defender *mo
 }
 
 // dealDamageToPlayer 對玩家造成傷害
-// 從生命區翻開指定數量的卡片，檢查觸發效果，然後將卡片放入場外區
+// 從生命區翻開指定數量的卡片，有可發動觸發效果的卡片由玩家選擇是否發動，其餘卡片直接放入場外區
 func (e *gameEngine) dealDamageToPlayer(gameState *models.GameState, playerID uuid.UUID, damage int, result *ActionResult) {
 	player := gameState.Players[playerID]
 
@@ -578,38 +794,29 @@ func (e *gameEngine) dealDamageToPlayer(gameState *models.GameState, playerID uu
 		player.Board.LifeArea = player.Board.LifeArea[1:]
 		cardsRevealed++
 
-		// 檢查觸發效果
-		if card.TriggerEffect != "" && card.TriggerEffect != models.TriggerEffectNil {
-			// 玩家可以選擇是否發動觸發效果（這裡自動觸發，實際應該由玩家決定）
-			effect := models.CardEffect{
-				Type:        card.TriggerEffect,
-				Description: e.getTriggerEffectDescription(card.TriggerEffect, card.Color),
-			}
-
-			// 記錄觸發效果事件
-			result.EventsTriggered = append(result.EventsTriggered, GameEvent{
-				Type:      "TRIGGER_EFFECT",
-				Source:    &playerID,
-				Data:      map[string]interface{}{"card": card, "effect": effect},
-				Timestamp: time.Now(),
-			})
-
-			// 應用觸發效果
-			e.ApplyCardEffect(context.Background(), gameState, &effect, &card)
+		// 檢查可發動的觸發效果
+		if _, ok := lifeTriggerEffect(card, playerID); ok {
+			// 卡片暫時放在公開區域，由玩家選擇是否發動觸發效果後再放入場外區
+			player.Board.PublicArea = append(player.Board.PublicArea, card)
+			e.requestLifeTriggerDecision(gameState, playerID, card, result)
+		} else {
+			// 將卡片放入場外區
+			player.Board.Graveyard = append(player.Board.Graveyard, card)
 		}
 
-		// 將卡片放入場外區
-		player.Board.Graveyard = append(player.Board.Graveyard, card)
-
 		// 記錄生命區卡片被移除的事件
 		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
 			Type:      "LIFE_AREA_DAMAGED",
 			Source:    &playerID,
 			Data:      map[string]interface{}{"card": card, "remaining_life": len(player.Board.LifeArea)},
-			Timestamp: time.Now(),
+			Timestamp: e.clock.Now(),
 		})
 	}
 
+	if cardsRevealed > 0 {
+		emitTiming(gameState, models.TimingOnLifeDamage, playerID, nil, map[string]interface{}{"damage": cardsRevealed, "remaining_life": len(player.Board.LifeArea)})
+	}
+
 	logger.Debug("Player took damage",
 		zap.String("player", playerID.String()),
 		zap.Int("damage", damage),
@@ -617,13 +824,35 @@ func (e *gameEngine) dealDamageToPlayer(gameState *models.GameState, playerID uu
 		zap.Int("remaining_life", len(player.Board.LifeArea)))
 }
 
+// newSeed 產生遊戲的隨機種子
+// 若請求已指定種子則直接使用，否則由引擎的隨機數產生器產生
+func (e *gameEngine) newSeed(requested *int64) int64 {
+	if requested != nil {
+		return *requested
+	}
+
+	e.rngMu.Lock()
+	defer e.rngMu.Unlock()
+	return e.rng.Int64()
+}
+
 // shuffleDeck 洗牌
-// 使用Fisher-Yates算法隨機打亂卡組順序
-func (e *gameEngine) shuffleDeck(deck []models.Card) {
-	for i := len(deck) - 1; i > 0; i-- {
-		j := int(time.Now().UnixNano()) % (i + 1)
+// 使用遊戲種子與洗牌次數建立隨機數產生器並以Fisher-Yates算法打亂卡組順序，相同種子與動作記錄會得到相同結果
+func (e *gameEngine) shuffleDeck(gameState *models.GameState, deck []models.Card) {
+	rng := rand.New(rand.NewPCG(uint64(gameState.Seed), uint64(gameState.ShuffleCount)))
+	gameState.ShuffleCount++
+
+	rng.Shuffle(len(deck), func(i, j int) {
 		deck[i], deck[j] = deck[j], deck[i]
-	}
+	})
+}
+
+// newID 產生遊戲內的識別碼（待決選擇、觸發效果等）
+// 由遊戲種子與已產生的數量推導，相同種子與動作記錄會得到相同的識別碼，重播時動作中引用的識別碼仍然有效
+func newID(gameState *models.GameState) uuid.UUID {
+	id := uuid.NewSHA1(uuid.NameSpaceOID, fmt.Appendf(nil, "%d:%d", gameState.Seed, gameState.IDCount))
+	gameState.IDCount++
+	return id
 }
 
 // drawCard 抽牌
@@ -644,10 +873,11 @@ func (e *gameEngine) drawCard(player *models.Player) bool {
 func (e *gameEngine) processDrawCard(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
 	player := gameState.Players[action.PlayerID]
 	if e.drawCard(player) {
+		emitTiming(gameState, models.TimingOnDraw, action.PlayerID, nil, nil)
 		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
 			Type:      "CARD_DRAWN",
 			Source:    &action.PlayerID,
-			Timestamp: time.Now(),
+			Timestamp: e.clock.Now(),
 		})
 	} else {
 		result.Success = false
@@ -683,11 +913,12 @@ func (e *gameEngine) processExtraDraw(gameState *models.GameState, action *model
 
 	// 抽一張牌
 	if e.drawCard(player) {
+		emitTiming(gameState, models.TimingOnDraw, action.PlayerID, nil, nil)
 		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
 			Type:      "EXTRA_CARD_DRAWN",
 			Source:    &action.PlayerID,
 			Data:      map[string]interface{}{"ap_cost": 1},
-			Timestamp: time.Now(),
+			Timestamp: e.clock.Now(),
 		})
 
 		// 如果是在START階段使用額外抽牌，自動推進到MOVE階段
@@ -698,7 +929,7 @@ func (e *gameEngine) processExtraDraw(gameState *models.GameState, action *model
 				Type:      "PHASE_ADVANCED_BY_EXTRA_DRAW",
 				Source:    &action.PlayerID,
 				Data:      map[string]interface{}{"from_phase": "START", "to_phase": "MOVE"},
-				Timestamp: time.Now(),
+				Timestamp: e.clock.Now(),
 			})
 			logger.Debug("EXTRA_DRAW in START phase, auto-advancing to MOVE phase",
 				zap.String("game_id", action.GameID.String()),
@@ -753,74 +984,116 @@ func (e *gameEngine) processPlayCard(gameState *models.GameState, action *models
 		return
 	}
 
-	var energyCost map[string]int
-	if playedCard.EnergyCost != nil {
-		json.Unmarshal(playedCard.EnergyCost, &energyCost)
-		for color, required := range energyCost {
-			if player.Energy[color] < required {
-				result.Success = false
-				result.Error = fmt.Sprintf("insufficient %s energy: need %d, have %d", color, required, player.Energy[color])
-				return
-			}
-		}
-	}
-
-	player.Hand = append(player.Hand[:cardIndex], player.Hand[cardIndex+1:]...)
-	player.AP -= playedCard.APCost
-
-	for color, cost := range energyCost {
-		player.Energy[color] -= cost
-	}
-
-	cardInPlay := models.CardInPlay{
-		Card:      playedCard,
-		Status:    models.CardStatus{CanAct: false, CanAttack: false, CanBlock: true, IsActive: false, IsRested: true},
-		Modifiers: []models.CardModifier{},
-		Owner:     action.PlayerID,
+	// 能源需求：場上卡片產生的能源必須滿足需求，能源不會被消耗
+	energyCost := models.ParseEnergy(playedCard.EnergyCost)
+	if shortages := models.CheckEnergyRequirement(player.AvailableEnergy(), energyCost); len(shortages) > 0 {
+		result.Success = false
+		result.Error = fmt.Sprintf("insufficient %s energy: need %d, have %d", shortages[0].Color, shortages[0].Required, shortages[0].Available)
+		return
 	}
 
+	// 決定角色卡與場域卡登場的區域與位置，須在支付費用前完成檢查
+	var line *[]models.CardInPlay
+	var position models.Position
+	var raidBase *models.CardInPlay
 	switch playedCard.CardType {
 	case models.CardTypeCharacter:
+		// 突襲：疊加在場上符合條件的角色上，位置由原角色決定
+		if actionData.RaidTargetID != nil {
+			base, err := findRaidBase(player, playedCard, *actionData.RaidTargetID)
+			if err != nil {
+				result.Success = false
+				result.Error = err.Error()
+				return
+			}
+			raidBase = base
+			break
+		}
 		if actionData.Position == nil {
 			result.Success = false
 			result.Error = "position required for character cards"
 			return
 		}
-		cardInPlay.Position = *actionData.Position
 		// 將角色卡放入適當的區域（先預設放入能源線，後續可移至前線）
-		if actionData.Position != nil && actionData.Position.Zone == "front_line" {
-			player.Board.FrontLine = append(player.Board.FrontLine, cardInPlay)
-		} else {
-			player.Board.EnergyLine = append(player.Board.EnergyLine, cardInPlay)
+		position.Zone = "energy_line"
+		line = &player.Board.EnergyLine
+		if actionData.Position.Zone == "front_line" {
+			position.Zone = "front_line"
+			line = &player.Board.FrontLine
 		}
+		slot, err := availableSlot(*line, &actionData.Position.Slot, nil)
+		if err != nil {
+			result.Success = false
+			result.Error = fmt.Sprintf("cannot play to %s: %v", position.Zone, err)
+			return
+		}
+		position.Slot = slot
 	case models.CardTypeField:
 		// 場域卡只能放在能源線
-		player.Board.EnergyLine = append(player.Board.EnergyLine, cardInPlay)
-	case models.CardTypeEvent:
+		position.Zone = "energy_line"
+		line = &player.Board.EnergyLine
+		slot, err := availableSlot(*line, nil, nil)
+		if err != nil {
+			result.Success = false
+			result.Error = fmt.Sprintf("cannot play to %s: %v", position.Zone, err)
+			return
+		}
+		position.Slot = slot
+	}
+
+	player.Hand = append(player.Hand[:cardIndex], player.Hand[cardIndex+1:]...)
+	player.AP -= playedCard.APCost
+
+	switch {
+	case raidBase != nil:
+		e.raidOnto(gameState, playedCard, raidBase, result)
+		e.runKeywordHooks(gameState, raidBase, result, func(h KeywordHandler, kc *KeywordContext) error {
+			return h.OnPlay(kc)
+		})
+	case playedCard.CardType == models.CardTypeCharacter || playedCard.CardType == models.CardTypeField:
+		*line = append(*line, models.CardInPlay{
+			Card:       playedCard,
+			Position:   position,
+			Status:     models.CardStatus{CanAct: false, CanAttack: false, CanBlock: true, IsActive: false, IsRested: true},
+			Modifiers:  []models.CardModifier{},
+			Owner:      action.PlayerID,
+			PlayedTurn: gameState.Turn,
+		})
+		played := findCardInLine(*line, playedCard.ID)
+		e.runKeywordHooks(gameState, played, result, func(h KeywordHandler, kc *KeywordContext) error {
+			return h.OnPlay(kc)
+		})
+	case playedCard.CardType == models.CardTypeEvent:
 		if playedCard.TriggerEffect != "" && playedCard.TriggerEffect != models.TriggerEffectNil {
 			// Convert simple trigger effect string to CardEffect struct
 			effect := models.CardEffect{
 				Type:        playedCard.TriggerEffect,
 				Description: e.getTriggerEffectDescription(playedCard.TriggerEffect, playedCard.Color),
 			}
-			e.ApplyCardEffect(context.Background(), gameState, &effect, &playedCard)
+			if err := e.ApplyCardEffect(context.Background(), gameState, &effect, &playedCard, result); err != nil {
+				logger.Debug("Event effect not applied",
+					zap.Error(err),
+					zap.String("card", playedCard.Name))
+			}
 		}
 		player.Board.Graveyard = append(player.Board.Graveyard, playedCard)
 	}
 
+	emitTiming(gameState, models.TimingOnPlay, action.PlayerID, &playedCard, nil)
+
 	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
 		Type:      "CARD_PLAYED",
 		Source:    &action.PlayerID,
 		Data:      map[string]interface{}{"card": playedCard},
-		Timestamp: time.Now(),
+		Timestamp: e.clock.Now(),
 	})
 }
 
-// processAttack 處理攻擊動作
-// 驗證攻擊者和防禦者是否存在和有效、計算傷害、處理角色被摧毀
+// processAttack 處理攻擊宣告
+// 驗證攻擊者和攻擊目標、將攻擊者轉為休息狀態，並開啟防禦方的防禦窗口或直接解決攻擊
 func (e *gameEngine) processAttack(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
-	var actionData models.ActionData
-	if err := json.Unmarshal(action.ActionData, &actionData); err != nil {
+	actionData, err := parseActionData(action.ActionData)
+	if err != nil {
 		result.Success = false
 		result.Error = "invalid action data"
 		return
@@ -833,23 +1106,14 @@ func (e *gameEngine) processAttack(gameState *models.GameState, action *models.G
 	}
 
 	player := gameState.Players[action.PlayerID]
-	var attacker *models.CardInPlay
-
-	// 在前線尋找攻擊者
-	for i := range player.Board.FrontLine {
-		if player.Board.FrontLine[i].Card.ID == *actionData.CardID {
-			attacker = &player.Board.FrontLine[i]
-			break
-		}
-	}
-
+	attacker := findCardInLine(player.Board.FrontLine, *actionData.CardID)
 	if attacker == nil {
 		result.Success = false
 		result.Error = "attacker not found"
 		return
 	}
 
-	if !attacker.Status.CanAttack || !attacker.Status.IsActive {
+	if !canAttack(attacker, gameState) {
 		result.Success = false
 		result.Error = "character cannot attack"
 		return
@@ -858,140 +1122,543 @@ func (e *gameEngine) processAttack(gameState *models.GameState, action *models.G
 	opponentID := e.getOpponentID(gameState, action.PlayerID)
 	opponent := gameState.Players[*opponentID]
 
-	// 設置攻擊者為休息狀態
-	attacker.Status.IsActive = false
-	attacker.Status.IsRested = true
-	attacker.Status.CanAttack = false
+	pendingAttack := &models.PendingAttack{
+		AttackerID:      attacker.Card.ID,
+		AttackingPlayer: action.PlayerID,
+		DefendingPlayer: *opponentID,
+		TargetType:      "player",
+	}
 
 	// 判斷攻擊目標類型
-	if actionData.TargetType == "player" || (actionData.TargetType == "" && actionData.TargetID == nil) {
-		// 攻擊玩家：造成生命區傷害
-		damage := 1
-
-		// 檢查ダメージ●關鍵字（造成2點傷害）
-		for _, keyword := range attacker.Card.Keywords {
-			if keyword == "ダメージ●" {
-				damage = 2
-				break
-			}
-		}
-
-		e.dealDamageToPlayer(gameState, *opponentID, damage, result)
-
-		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
-			Type:      "PLAYER_ATTACKED",
-			Source:    actionData.CardID,
-			Target:    opponentID,
-			Data:      map[string]interface{}{"damage": damage},
-			Timestamp: time.Now(),
-		})
-
-	} else {
-		// 攻擊角色卡：進行BP比較戰鬥
+	if actionData.TargetType == "character" || (actionData.TargetType == "" && actionData.TargetID != nil) {
 		if actionData.TargetID == nil {
 			result.Success = false
 			result.Error = "target character required"
 			return
 		}
-
-		var defender *models.CardInPlay
-		// 在對手前線尋找防禦者
-		for i := range opponent.Board.FrontLine {
-			if opponent.Board.FrontLine[i].Card.ID == *actionData.TargetID {
-				defender = &opponent.Board.FrontLine[i]
-				break
-			}
-		}
-
-		if defender == nil {
+		if findCardInLine(opponent.Board.FrontLine, *actionData.TargetID) == nil {
 			result.Success = false
 			result.Error = "target character not found"
 			return
 		}
+		pendingAttack.TargetType = "character"
+		pendingAttack.TargetID = actionData.TargetID
+	}
 
-		// 根據Union Arena規則進行BP比較
-		attackerBP := *attacker.Card.BP
-		defenderBP := *defender.Card.BP
+	// 攻擊方的關鍵字可以改變攻擊目標的限制與是否可被防禦（例如狙い撃ち）
+	attackContext := &AttackContext{Attack: pendingAttack}
+	if err := e.runKeywordHooks(gameState, attacker, result, func(h KeywordHandler, kc *KeywordContext) error {
+		return h.OnAttack(kc, attackContext)
+	}); err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		return
+	}
 
-		// 計算修正器加成
-		for _, modifier := range attacker.Modifiers {
-			if modifier.Type == "bp_boost" {
-				if boost, ok := modifier.Value.(int); ok {
-					attackerBP += boost
-				}
-			}
-		}
+	if pendingAttack.TargetType == "character" && !attackContext.CanTargetCharacter {
+		result.Success = false
+		result.Error = "only characters with 狙い撃ち can attack a character"
+		return
+	}
 
-		for _, modifier := range defender.Modifiers {
-			if modifier.Type == "bp_boost" {
-				if boost, ok := modifier.Value.(int); ok {
-					defenderBP += boost
-				}
-			}
-		}
+	// 設置攻擊者為休息狀態
+	attacker.Status.IsActive = false
+	attacker.Status.IsRested = true
+	attacker.Status.CanAttack = false
 
-		// 比較BP決定戰鬥結果
-		if attackerBP >= defenderBP {
-			// 攻擊方獲勝，防禦方角色卡退場
-			// 從前線移除被擊敗的卡片
-			for i, char := range opponent.Board.FrontLine {
-				if char.Card.ID == defender.Card.ID {
-					// 將被擊敗的卡片移至場外區
-					opponent.Board.OutsideArea = append(opponent.Board.OutsideArea, defender.Card)
-					// 從前線移除
-					opponent.Board.FrontLine = append(opponent.Board.FrontLine[:i], opponent.Board.FrontLine[i+1:]...)
-					opponent.Board.Graveyard = append(opponent.Board.Graveyard, defender.Card)
-					break
-				}
-			}
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:      "ATTACK_DECLARED",
+		Source:    actionData.CardID,
+		Target:    pendingAttack.TargetID,
+		Data:      map[string]interface{}{"target_type": pendingAttack.TargetType, "unblockable": attackContext.Unblockable},
+		Timestamp: e.clock.Now(),
+	})
 
-			result.EventsTriggered = append(result.EventsTriggered, GameEvent{
-				Type:      "CHARACTER_DESTROYED",
-				Target:    actionData.TargetID,
-				Data:      map[string]interface{}{"reason": "battle_defeat", "attacker_bp": attackerBP, "defender_bp": defenderBP},
-				Timestamp: time.Now(),
-			})
+	// 攻擊時觸發的效果在防禦與戰鬥之前處理
+	emitTiming(gameState, models.TimingOnAttack, action.PlayerID, &attacker.Card, map[string]interface{}{"target_type": pendingAttack.TargetType})
+	e.resolveTriggers(gameState, result)
 
+	// 攻擊可被防禦時，若對手有可防禦的角色則開啟防禦窗口
+	if !attackContext.Unblockable {
+		blockers := e.getEligibleBlockers(gameState, opponent)
+		if len(blockers) > 0 {
+			gameState.PendingAttack = pendingAttack
 			result.EventsTriggered = append(result.EventsTriggered, GameEvent{
-				Type:      "BATTLE_WON",
+				Type:      "BLOCK_WINDOW_OPENED",
 				Source:    actionData.CardID,
-				Data:      map[string]interface{}{"attacker_bp": attackerBP, "defender_bp": defenderBP},
-				Timestamp: time.Now(),
+				Target:    opponentID,
+				Data:      map[string]interface{}{"eligible_blockers": blockers},
+				Timestamp: e.clock.Now(),
 			})
-		} else {
-			// 防禦方獲勝，攻擊方戰敗但不退場
+			return
+		}
+	}
+
+	e.resolveAttack(gameState, pendingAttack, nil, result)
+}
+
+// processBlock 處理防禦宣告
+// 防禦方可選擇前線1張活動且可防禦的角色進行防禦（轉為休息狀態），或不指定卡片放棄防禦
+func (e *gameEngine) processBlock(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
+	actionData, err := parseActionData(action.ActionData)
+	if err != nil {
+		result.Success = false
+		result.Error = "invalid action data"
+		return
+	}
+
+	pendingAttack := gameState.PendingAttack
+	defender := gameState.Players[pendingAttack.DefendingPlayer]
+
+	// 未指定防禦角色：放棄防禦，攻擊照原目標解決
+	if actionData.CardID == nil {
+		gameState.PendingAttack = nil
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "BLOCK_DECLINED",
+			Source:    &action.PlayerID,
+			Target:    &pendingAttack.AttackerID,
+			Timestamp: e.clock.Now(),
+		})
+		e.resolveAttack(gameState, pendingAttack, nil, result)
+		return
+	}
+
+	blocker := findCardInLine(defender.Board.FrontLine, *actionData.CardID)
+	if blocker == nil {
+		result.Success = false
+		result.Error = "blocker not found"
+		return
+	}
+
+	if !canBlock(blocker, gameState) {
+		result.Success = false
+		result.Error = "character cannot block"
+		return
+	}
+
+	if err := e.runKeywordHooks(gameState, blocker, result, func(h KeywordHandler, kc *KeywordContext) error {
+		return h.OnBlock(kc, &AttackContext{Attack: pendingAttack})
+	}); err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		return
+	}
+
+	// 防禦角色轉為休息狀態
+	blocker.Status.IsActive = false
+	blocker.Status.IsRested = true
+
+	gameState.PendingAttack = nil
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:      "BLOCK_DECLARED",
+		Source:    actionData.CardID,
+		Target:    &pendingAttack.AttackerID,
+		Timestamp: e.clock.Now(),
+	})
+
+	// 防禦時觸發的效果在戰鬥之前處理，效果可能使防禦角色離場
+	emitTiming(gameState, models.TimingOnBlock, action.PlayerID, &blocker.Card, nil)
+	e.resolveTriggers(gameState, result)
+
+	blocker = findCardInLine(defender.Board.FrontLine, *actionData.CardID)
+	if blocker == nil {
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "ATTACK_CANCELLED",
+			Source:    &pendingAttack.AttackerID,
+			Target:    actionData.CardID,
+			Data:      map[string]interface{}{"reason": "blocker left the front line"},
+			Timestamp: e.clock.Now(),
+		})
+		return
+	}
+
+	e.resolveAttack(gameState, pendingAttack, blocker, result)
+}
+
+// resolveAttack 解決攻擊（戰鬥處理）
+// 有防禦角色或攻擊對象為角色時進行BP比較，否則對防禦方玩家造成傷害
+func (e *gameEngine) resolveAttack(gameState *models.GameState, pendingAttack *models.PendingAttack, blocker *models.CardInPlay, result *ActionResult) {
+	attackingPlayer := gameState.Players[pendingAttack.AttackingPlayer]
+	defendingPlayer := gameState.Players[pendingAttack.DefendingPlayer]
+
+	attacker := findCardInLine(attackingPlayer.Board.FrontLine, pendingAttack.AttackerID)
+	if attacker == nil {
+		// 攻擊角色已不在前線，攻擊不產生任何結果
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "ATTACK_CANCELLED",
+			Source:    &pendingAttack.AttackerID,
+			Data:      map[string]interface{}{"reason": "attacker left the front line"},
+			Timestamp: e.clock.Now(),
+		})
+		return
+	}
+
+	defender := blocker
+	if defender == nil && pendingAttack.TargetType == "character" {
+		defender = findCardInLine(defendingPlayer.Board.FrontLine, *pendingAttack.TargetID)
+		if defender == nil {
 			result.EventsTriggered = append(result.EventsTriggered, GameEvent{
-				Type:      "BATTLE_LOST",
-				Source:    actionData.CardID,
-				Data:      map[string]interface{}{"attacker_bp": attackerBP, "defender_bp": defenderBP},
-				Timestamp: time.Now(),
+				Type:      "ATTACK_CANCELLED",
+				Source:    &pendingAttack.AttackerID,
+				Target:    pendingAttack.TargetID,
+				Data:      map[string]interface{}{"reason": "target left the front line"},
+				Timestamp: e.clock.Now(),
 			})
+			return
 		}
 	}
 
+	damage := &DamageContext{Attack: pendingAttack, Blocked: blocker != nil}
+	if defender == nil {
+		// 攻擊玩家：造成1點生命區傷害，關鍵字可以改變傷害數值
+		damage.Amount = 1
+	} else {
+		defenderCard := defender.Card
+		damage.Defender = &defenderCard
+		damage.DefenderRetired = e.resolveBattle(gameState, attacker, defender, pendingAttack.DefendingPlayer, result)
+	}
+
+	// 攻擊方的關鍵字決定最終傷害（例如ダメージN、インパクトN）
+	if err := e.runKeywordHooks(gameState, attacker, result, func(h KeywordHandler, kc *KeywordContext) error {
+		return h.OnDamage(kc, damage)
+	}); err != nil {
+		logger.Error("Failed to apply damage keywords",
+			zap.Error(err),
+			zap.String("attacker", attacker.Card.Name))
+	}
+
+	if damage.Amount > 0 {
+		e.dealDamageToPlayer(gameState, pendingAttack.DefendingPlayer, damage.Amount, result)
+
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "PLAYER_ATTACKED",
+			Source:    &pendingAttack.AttackerID,
+			Target:    &pendingAttack.DefendingPlayer,
+			Data:      map[string]interface{}{"damage": damage.Amount, "blocked": damage.Blocked},
+			Timestamp: e.clock.Now(),
+		})
+	}
+
 	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
 		Type:      "ATTACK_PERFORMED",
-		Source:    actionData.CardID,
-		Target:    actionData.TargetID,
-		Data:      map[string]interface{}{"target_type": actionData.TargetType},
-		Timestamp: time.Now(),
+		Source:    &pendingAttack.AttackerID,
+		Target:    pendingAttack.TargetID,
+		Data:      map[string]interface{}{"target_type": pendingAttack.TargetType, "blocked": blocker != nil},
+		Timestamp: e.clock.Now(),
+	})
+}
+
+// resolveBattle 處理角色之間的戰鬥
+// 根據Union Arena規則比較BP：攻擊方BP ≥ 防禦方BP時防禦方退場，否則攻擊方戰敗但不退場；返回防禦方是否退場
+func (e *gameEngine) resolveBattle(gameState *models.GameState, attacker, defender *models.CardInPlay, defendingPlayerID uuid.UUID, result *ActionResult) bool {
+	attackerID := attacker.Card.ID
+	defenderID := defender.Card.ID
+
+	attackerBP := EffectiveBP(attacker, gameState)
+	defenderBP := EffectiveBP(defender, gameState)
+
+	// 比較BP決定戰鬥結果
+	if attackerBP >= defenderBP {
+		// 攻擊方獲勝，防禦方角色卡退場（置於場外區）
+		retireCharacter(gameState, gameState.Players[defendingPlayerID], defenderID)
+
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "CHARACTER_DESTROYED",
+			Target:    &defenderID,
+			Data:      map[string]interface{}{"reason": "battle_defeat", "attacker_bp": attackerBP, "defender_bp": defenderBP},
+			Timestamp: e.clock.Now(),
+		})
+
+		result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+			Type:      "BATTLE_WON",
+			Source:    &attackerID,
+			Target:    &defenderID,
+			Data:      map[string]interface{}{"attacker_bp": attackerBP, "defender_bp": defenderBP},
+			Timestamp: e.clock.Now(),
+		})
+		return true
+	}
+
+	// 防禦方獲勝，攻擊方戰敗但不退場
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:      "BATTLE_LOST",
+		Source:    &attackerID,
+		Target:    &defenderID,
+		Data:      map[string]interface{}{"attacker_bp": attackerBP, "defender_bp": defenderBP},
+		Timestamp: e.clock.Now(),
 	})
+	return false
+}
+
+// retireCharacter 使角色退場
+// 將指定角色從前線或能源線移除並放置到場外區，並發出退場時點事件；若角色不在場上則返回false
+func retireCharacter(gameState *models.GameState, player *models.Player, cardID uuid.UUID) bool {
+	card, removed := removeCardFromBoard(player, cardID)
+	if !removed {
+		return false
+	}
+	player.Board.OutsideArea = append(player.Board.OutsideArea, card.Card)
+	releaseStack(player, &card)
+
+	for playerID, p := range gameState.Players {
+		if p == player {
+			emitTiming(gameState, models.TimingOnRetire, playerID, &card.Card, nil)
+		}
+	}
+	return true
+}
+
+// releaseStack 處理離場卡片下方疊加的卡片
+// 根據規則，突襲狀態的卡片離場時，下方的卡片全部直接置於場外區，且不視為退場
+func releaseStack(player *models.Player, card *models.CardInPlay) {
+	player.Board.OutsideArea = append(player.Board.OutsideArea, card.Stack...)
+	card.Stack = nil
+}
+
+// refreshEnergy 更新所有玩家顯示用的能源數值
+// 能源由場上卡片決定，每次狀態改變後重新計算
+func refreshEnergy(gameState *models.GameState) {
+	for _, player := range gameState.Players {
+		player.Energy = player.AvailableEnergy()
+	}
+}
+
+// removeCardFromBoard 將卡片從前線或能源線移除
+// 只移除卡片而不放置到其他區域，由呼叫者決定卡片的去向；若卡片不在場上則返回false
+func removeCardFromBoard(player *models.Player, cardID uuid.UUID) (models.CardInPlay, bool) {
+	for i, char := range player.Board.FrontLine {
+		if char.Card.ID == cardID {
+			player.Board.FrontLine = append(player.Board.FrontLine[:i], player.Board.FrontLine[i+1:]...)
+			return char, true
+		}
+	}
+	for i, char := range player.Board.EnergyLine {
+		if char.Card.ID == cardID {
+			player.Board.EnergyLine = append(player.Board.EnergyLine[:i], player.Board.EnergyLine[i+1:]...)
+			return char, true
+		}
+	}
+	return models.CardInPlay{}, false
+}
+
+// availableSlot 取得區域中可放置卡片的位置
+// requested 為nil時返回第一個空位，否則檢查指定位置是否在範圍內且未被佔用；vacating 為即將離開該區域的卡片
+func availableSlot(line []models.CardInPlay, requested *int, vacating *uuid.UUID) (int, error) {
+	occupied := make(map[int]bool, len(line))
+	for _, card := range line {
+		if vacating != nil && card.Card.ID == *vacating {
+			continue
+		}
+		occupied[card.Position.Slot] = true
+	}
+
+	if len(occupied) >= lineCapacity {
+		return 0, fmt.Errorf("line is full")
+	}
+
+	if requested != nil {
+		if *requested < 0 || *requested >= lineCapacity {
+			return 0, fmt.Errorf("slot must be between 0 and %d", lineCapacity-1)
+		}
+		if occupied[*requested] {
+			return 0, fmt.Errorf("slot %d is occupied", *requested)
+		}
+		return *requested, nil
+	}
+
+	for slot := 0; slot < lineCapacity; slot++ {
+		if !occupied[slot] {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("line is full")
+}
+
+// hasKeyword 檢查卡片是否持有指定關鍵字
+// 比對解析後的關鍵字名稱，因此 ダメージ2 等帶數值的關鍵字也能以名稱查詢
+func hasKeyword(card models.Card, name string) bool {
+	for _, keyword := range models.ParseKeywords(card.Keywords) {
+		if keyword.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findCardOnBoard 在玩家的前線與能源線中尋找場上卡片
+// 返回指向區域切片中元素的指標，找不到時返回nil
+func findCardOnBoard(player *models.Player, cardID uuid.UUID) *models.CardInPlay {
+	if card := findCardInLine(player.Board.FrontLine, cardID); card != nil {
+		return card
+	}
+	return findCardInLine(player.Board.EnergyLine, cardID)
+}
+
+// getEligibleBlockers 獲取可防禦的角色
+// 只有前線上活動狀態、可防禦且沒有不能防禦修正的角色才能進行防禦
+func (e *gameEngine) getEligibleBlockers(gameState *models.GameState, player *models.Player) []uuid.UUID {
+	blockers := []uuid.UUID{}
+	for i := range player.Board.FrontLine {
+		char := &player.Board.FrontLine[i]
+		if canBlock(char, gameState) {
+			blockers = append(blockers, char.Card.ID)
+		}
+	}
+	return blockers
+}
+
+// findCardInLine 在指定區域中尋找場上卡片
+// 返回指向區域切片中元素的指標，找不到時返回nil
+func findCardInLine(line []models.CardInPlay, cardID uuid.UUID) *models.CardInPlay {
+	for i := range line {
+		if line[i].Card.ID == cardID {
+			return &line[i]
+		}
+	}
+	return nil
+}
+
+// parseActionData 解析動作資料
+// 允許空的動作資料（如 []、{}、null），此時返回零值
+func parseActionData(raw json.RawMessage) (models.ActionData, error) {
+	var actionData models.ActionData
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "[]" || trimmed == "null" {
+		return actionData, nil
+	}
+	if err := json.Unmarshal(raw, &actionData); err != nil {
+		return actionData, err
+	}
+	return actionData, nil
 }
 
 // processMoveCharacter 處理角色移動動作
-// 目前僅觸發角色移動事件，待後續實現具體移動邏輯
+// 將活動狀態的角色在能源線與前線之間移動，前線角色需持有ステップ才能移回能源線；目的地已滿時需以 target_id 指定放置到移除區的卡片
 func (e *gameEngine) processMoveCharacter(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
+	actionData, err := parseActionData(action.ActionData)
+	if err != nil {
+		result.Success = false
+		result.Error = "invalid action data"
+		return
+	}
+
+	if actionData.CardID == nil {
+		result.Success = false
+		result.Error = "card_id is required"
+		return
+	}
+
+	player := gameState.Players[action.PlayerID]
+	cardID := *actionData.CardID
+
+	fromZone, toZone := "energy_line", "front_line"
+	from, to := &player.Board.EnergyLine, &player.Board.FrontLine
+	if findCardInLine(player.Board.FrontLine, cardID) != nil {
+		fromZone, toZone = "front_line", "energy_line"
+		from, to = to, from
+	}
+
+	character := findCardInLine(*from, cardID)
+	if character == nil {
+		result.Success = false
+		result.Error = "character not found on your board"
+		return
+	}
+
+	if actionData.Position != nil && actionData.Position.Zone != "" && actionData.Position.Zone != toZone {
+		result.Success = false
+		result.Error = fmt.Sprintf("cannot move from %s to %s", fromZone, actionData.Position.Zone)
+		return
+	}
+
+	if character.Card.CardType != models.CardTypeCharacter {
+		result.Success = false
+		result.Error = "only character cards can be moved"
+		return
+	}
+
+	if character.Status.IsRested {
+		result.Success = false
+		result.Error = "cannot move a rested character"
+		return
+	}
+
+	if character.PlayedTurn == gameState.Turn {
+		result.Success = false
+		result.Error = "cannot move a character played this turn"
+		return
+	}
+
+	if fromZone == "front_line" && !hasKeyword(character.Card, models.KeywordStep) {
+		result.Success = false
+		result.Error = "only characters with ステップ can move back to the energy line"
+		return
+	}
+
+	// 目的地已滿時，必須先從該區域選擇卡片放置到移除區
+	var replacedID *uuid.UUID
+	if len(*to) >= lineCapacity {
+		if actionData.TargetID == nil {
+			result.Success = false
+			result.Error = fmt.Sprintf("%s is full: target_id must name a card to put into the remove area", toZone)
+			return
+		}
+		if findCardInLine(*to, *actionData.TargetID) == nil {
+			result.Success = false
+			result.Error = "target card not found in " + toZone
+			return
+		}
+		replacedID = actionData.TargetID
+	}
+
+	var requestedSlot *int
+	if actionData.Position != nil {
+		requestedSlot = &actionData.Position.Slot
+	}
+	slot, err := availableSlot(*to, requestedSlot, replacedID)
+	if err != nil {
+		result.Success = false
+		result.Error = fmt.Sprintf("cannot move to %s: %v", toZone, err)
+		return
+	}
+
+	eventData := map[string]interface{}{"from": fromZone, "to": toZone, "slot": slot}
+	if replacedID != nil {
+		replaced, _ := removeCardFromBoard(player, *replacedID)
+		player.Board.RemoveArea = append(player.Board.RemoveArea, replaced.Card)
+		releaseStack(player, &replaced)
+		eventData["removed"] = replaced.Card
+	}
+
+	moved, _ := removeCardFromBoard(player, cardID)
+	moved.Position = models.Position{Zone: toZone, Slot: slot}
+	moved.Status.CanAttack = toZone == "front_line"
+	*to = append(*to, moved)
+
 	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
 		Type:      "CHARACTER_MOVED",
 		Source:    &action.PlayerID,
-		Timestamp: time.Now(),
+		Target:    &cardID,
+		Data:      eventData,
+		Timestamp: e.clock.Now(),
 	})
+
+	logger.Debug("Character moved",
+		zap.String("player", action.PlayerID.String()),
+		zap.String("card", moved.Card.Name),
+		zap.String("to", toZone),
+		zap.Int("slot", slot))
 }
 
 // processEndPhase 處理結束階段動作
-// 推進到下一個階段並更新遊戲狀態
+// 推進到下一個階段並更新遊戲狀態，在結束階段時則結束回合
 func (e *gameEngine) processEndPhase(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
-	newGameState, err := e.AdvancePhase(context.Background(), action.GameID)
+	if gameState.Phase == models.EndPhase {
+		e.finishTurn(gameState, result)
+		return
+	}
+
+	newGameState, err := e.advancePhase(context.Background(), gameState)
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
@@ -1004,21 +1671,51 @@ func (e *gameEngine) processEndPhase(gameState *models.GameState, action *models
 // processEndTurn 處理結束回合動作
 // 推進到下一個回合並更新遊戲狀態
 func (e *gameEngine) processEndTurn(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
+	e.finishTurn(gameState, result)
+}
+
+// finishTurn 結束當前回合
+// 進入結束階段並發出回合結束時點，處理觸發效果後檢查手牌上限；需要玩家做出選擇時先暫停（選擇解決後再繼續結束回合），否則推進到下一回合
+func (e *gameEngine) finishTurn(gameState *models.GameState, result *ActionResult) {
+	if !gameState.EndingTurn {
+		gameState.Phase = models.EndPhase
+		gameState.EndingTurn = true
+		emitTiming(gameState, models.TimingEndOfTurn, gameState.ActivePlayer, nil, map[string]interface{}{"turn": gameState.Turn})
+	}
+
+	e.resolveTriggers(gameState, result)
+	if len(gameState.PendingDecisions) > 0 {
+		result.NextPhase = &gameState.Phase
+		return
+	}
+
+	if e.requestHandLimitDecision(gameState, gameState.ActivePlayer, result) {
+		result.NextPhase = &gameState.Phase
+		return
+	}
+
+	if err := e.turnManager.ProcessPhaseStart(context.Background(), gameState, models.EndPhase); err != nil {
+		logger.Error("Failed to process end phase", zap.Error(err))
+	}
+
+	gameState.EndingTurn = false
 	newGameState := e.advanceTurn(gameState)
 	result.GameState = newGameState
 	result.NextPhase = &newGameState.Phase
 }
 
 // processSurrender 處理投降動作
-// 確定對手為勝利者並觸發遊戲結束事件
+// 記錄投降的玩家，之後的勝負檢查判定對手獲勝並觸發遊戲結束事件
 func (e *gameEngine) processSurrender(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
-	opponentID := e.getOpponentID(gameState, action.PlayerID)
+	playerID := action.PlayerID
+	gameState.SurrenderedBy = &playerID
+}
 
-	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
-		Type:      "GAME_ENDED",
-		Data:      map[string]interface{}{"winner": opponentID, "reason": "opponent surrendered"},
-		Timestamp: time.Now(),
-	})
+// processConnectionLost 處理斷線判負
+// 記錄未重新連線的玩家，之後的勝負檢查判定對手獲勝並觸發遊戲結束事件
+func (e *gameEngine) processConnectionLost(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
+	playerID := action.PlayerID
+	gameState.ConnectionLost = &playerID
 }
 
 // advanceTurn 推進到下一回合
@@ -1066,15 +1763,14 @@ func (e *gameEngine) advanceTurn(gameState *models.GameState) *models.GameState
 
 	// 先攻玩家第一個回合不抽卡
 	if !(isFirstPlayer && gameState.Turn == 1) {
-		e.drawCard(player)
+		if e.drawCard(player) {
+			emitTiming(gameState, models.TimingOnDraw, gameState.ActivePlayer, nil, nil)
+		}
 	}
 
-	for i := range player.Board.FrontLine {
-		player.Board.FrontLine[i].Status.CanAttack = true
-		player.Board.FrontLine[i].Status.IsActive = true
-		player.Board.FrontLine[i].Status.IsRested = false
-	}
+	activateCharacters(player)
 
+	emitTiming(gameState, models.TimingStartOfTurn, gameState.ActivePlayer, nil, map[string]interface{}{"turn": gameState.Turn})
 	return gameState
 }
 
@@ -1125,8 +1821,28 @@ func (e *gameEngine) validateAttack(gameState *models.GameState, action *models.
 	return nil
 }
 
+// validateDuringPendingAttack 驗證防禦窗口期間的動作
+// 防禦窗口開啟時只有防禦方可以宣告防禦，其他動作（投降除外）都必須等待攻擊解決
+func (e *gameEngine) validateDuringPendingAttack(gameState *models.GameState, action *models.GameAction) error {
+	if gameState.Players[action.PlayerID] == nil {
+		return fmt.Errorf("player not found")
+	}
+
+	switch action.ActionType {
+	case models.ActionTypeBlock:
+		if action.PlayerID != gameState.PendingAttack.DefendingPlayer {
+			return fmt.Errorf("only the defending player can block")
+		}
+		return nil
+	case models.ActionTypeSurrender:
+		return nil
+	default:
+		return fmt.Errorf("waiting for defending player to declare block")
+	}
+}
+
 // validateMoveCharacter 驗證角色移動動作
-// 檢查是否在移動階段，只有移動階段才能移動角色
+// 檢查是否在移動階段，只有移動階段才能移動角色；角色狀態與區域限制在處理時檢查
 func (e *gameEngine) validateMoveCharacter(gameState *models.GameState, action *models.GameAction) error {
 	if gameState.Phase != models.MovePhase {
 		return fmt.Errorf("can only move characters during move phase")
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"go.uber.org/zap"
+)
+
+// KeywordHandler 關鍵字能力處理器
+// 每個方法對應一個遊戲時點，不需要處理的時點可嵌入 baseKeywordHandler 使用預設的空實現
+type KeywordHandler interface {
+	// OnPlay 持有關鍵字的卡片登場後
+	OnPlay(kc *KeywordContext) error
+	// OnAttack 持有關鍵字的角色宣告攻擊時，在攻擊目標確認前呼叫，返回錯誤時攻擊宣告無效
+	OnAttack(kc *KeywordContext, attack *AttackContext) error
+	// OnBlock 持有關鍵字的角色宣告防禦時，返回錯誤時防禦宣告無效
+	OnBlock(kc *KeywordContext, attack *AttackContext) error
+	// OnDamage 持有關鍵字的角色的攻擊解決後，決定對防禦方玩家造成的傷害
+	OnDamage(kc *KeywordContext, damage *DamageContext) error
+}
+
+// KeywordContext 關鍵字處理器可使用的遊戲資料
+type KeywordContext struct {
+	GameState *models.GameState
+	Card      *models.CardInPlay // 持有關鍵字的卡片
+	Keyword   models.Keyword     // 解析後的關鍵字與數值參數
+	Result    *ActionResult
+}
+
+// AttackContext 攻擊宣告與防禦時的攻擊資料
+type AttackContext struct {
+	Attack             *models.PendingAttack
+	CanTargetCharacter bool // 是否可以指定對手前線的角色為攻擊對象
+	Unblockable        bool // 攻擊是否無法被防禦
+}
+
+// DamageContext 攻擊解決後的傷害資料
+type DamageContext struct {
+	Attack          *models.PendingAttack
+	Blocked         bool
+	Defender        *models.Card // 防禦或被攻擊的角色，攻擊玩家且未被防禦時為nil
+	DefenderRetired bool         // 防禦或被攻擊的角色是否因戰鬥退場
+	Amount          int          // 對防禦方玩家造成的傷害
+}
+
+// baseKeywordHandler 提供所有時點的空實現
+type baseKeywordHandler struct{}
+
+func (baseKeywordHandler) OnPlay(kc *KeywordContext) error                          { return nil }
+func (baseKeywordHandler) OnAttack(kc *KeywordContext, attack *AttackContext) error { return nil }
+func (baseKeywordHandler) OnBlock(kc *KeywordContext, attack *AttackContext) error  { return nil }
+func (baseKeywordHandler) OnDamage(kc *KeywordContext, damage *DamageContext) error { return nil }
+
+// registerKeywordHandlers 註冊所有關鍵字處理器
+// 將關鍵字目錄中的關鍵字名稱映射到對應的處理器
+func (e *gameEngine) registerKeywordHandlers() {
+	e.keywordHandlers[models.KeywordSnipe] = snipeKeyword{}
+	e.keywordHandlers[models.KeywordDamage] = damageKeyword{}
+	e.keywordHandlers[models.KeywordImpact] = impactKeyword{}
+}
+
+// runKeywordHooks 依序呼叫卡片持有的關鍵字處理器
+// 目錄中沒有定義或沒有處理器的關鍵字會被忽略，任一處理器返回錯誤時立即停止
+func (e *gameEngine) runKeywordHooks(gameState *models.GameState, card *models.CardInPlay, result *ActionResult, hook func(KeywordHandler, *KeywordContext) error) error {
+	for _, keyword := range models.ParseKeywords(card.Card.Keywords) {
+		handler, exists := e.keywordHandlers[keyword.Name]
+		if !exists {
+			continue
+		}
+
+		kc := &KeywordContext{
+			GameState: gameState,
+			Card:      card,
+			Keyword:   keyword,
+			Result:    result,
+		}
+		if err := hook(handler, kc); err != nil {
+			logger.Debug("Keyword hook rejected",
+				zap.Error(err),
+				zap.String("keyword", keyword.Raw),
+				zap.String("card", card.Card.Name))
+			return err
+		}
+	}
+	return nil
+}
+
+// snipeKeyword 狙い撃ち
+// 可以指定對手前線的角色（無論活動或休息）為攻擊對象，指定角色時對手無法防禦
+type snipeKeyword struct{ baseKeywordHandler }
+
+func (snipeKeyword) OnAttack(kc *KeywordContext, attack *AttackContext) error {
+	attack.CanTargetCharacter = true
+	if attack.Attack.TargetType == "character" {
+		attack.Unblockable = true
+	}
+	return nil
+}
+
+// damageKeyword ダメージN
+// 攻擊未被防禦時，對玩家造成的傷害改為N點
+type damageKeyword struct{ baseKeywordHandler }
+
+func (damageKeyword) OnDamage(kc *KeywordContext, damage *DamageContext) error {
+	if damage.Defender == nil && !damage.Blocked {
+		damage.Amount = kc.Keyword.Value
+	}
+	return nil
+}
+
+// impactKeyword インパクトN
+// 攻擊使防禦或被攻擊的角色退場時，對對手玩家造成N點傷害；對方角色持有インパクト無効時無效
+type impactKeyword struct{ baseKeywordHandler }
+
+func (impactKeyword) OnDamage(kc *KeywordContext, damage *DamageContext) error {
+	if damage.Defender == nil || !damage.DefenderRetired {
+		return nil
+	}
+	if hasKeyword(*damage.Defender, models.KeywordImpactNegate) {
+		return nil
+	}
+	damage.Amount += kc.Keyword.Value
+	return nil
+}
@@ -0,0 +1,304 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxDecisionCandidates 待決選擇最多列出的選法數量，足以列出5個觸發能力的所有排列
+const maxDecisionCandidates = 120
+
+// LegalAction 玩家目前可以執行的動作
+// ActionData 可以直接作為動作資料送出
+type LegalAction struct {
+	ActionType string            `json:"action_type"`
+	ActionData models.ActionData `json:"action_data"`
+}
+
+// LegalActions 列出玩家目前可以執行的所有動作
+// 先根據遊戲狀態列出候選動作，再在遊戲狀態的副本上實際執行驗證，因此結果與 ProcessAction 的判定一致
+func (e *gameEngine) LegalActions(gameState *models.GameState, playerID uuid.UUID) []LegalAction {
+	legal := []LegalAction{}
+	if gameState == nil || gameState.Players[playerID] == nil {
+		return legal
+	}
+
+	snapshot, err := snapshotGameState(gameState)
+	if err != nil {
+		logger.Error("Failed to copy game state for legal actions", zap.Error(err))
+		return legal
+	}
+
+	for _, candidate := range e.candidateActions(gameState, playerID) {
+		if _, err := e.simulate(gameState, snapshot, playerID, candidate); err == nil {
+			legal = append(legal, candidate)
+		}
+	}
+	return legal
+}
+
+// SimulateAction 在遊戲狀態的副本上執行動作
+// 返回執行後的遊戲狀態副本，原本的遊戲狀態不會改變；動作不合法時返回錯誤
+func (e *gameEngine) SimulateAction(gameState *models.GameState, playerID uuid.UUID, candidate LegalAction) (*models.GameState, error) {
+	snapshot, err := snapshotGameState(gameState)
+	if err != nil {
+		logger.Error("Failed to copy game state for simulation", zap.Error(err))
+		return nil, err
+	}
+	return e.simulate(gameState, snapshot, playerID, candidate)
+}
+
+// simulate 驗證動作後，在由快照還原的遊戲狀態副本上執行
+// 同一個遊戲狀態的多個動作共用同一份快照，避免重複序列化
+func (e *gameEngine) simulate(gameState *models.GameState, snapshot []byte, playerID uuid.UUID, candidate LegalAction) (*models.GameState, error) {
+	actionData, err := json.Marshal(candidate.ActionData)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &models.GameAction{
+		ID:         uuid.New(),
+		PlayerID:   playerID,
+		ActionType: candidate.ActionType,
+		ActionData: actionData,
+		Turn:       gameState.Turn,
+		Phase:      gameState.Phase,
+	}
+	if err := e.ValidateAction(context.Background(), gameState, action); err != nil {
+		return nil, err
+	}
+
+	simulated := &models.GameState{}
+	if err := json.Unmarshal(snapshot, simulated); err != nil {
+		return nil, err
+	}
+
+	result := &ActionResult{Success: true, GameState: simulated}
+	e.applyAction(simulated, action, result)
+	if !result.Success {
+		return nil, errors.New(result.Error)
+	}
+	return simulated, nil
+}
+
+// snapshotGameState 序列化遊戲狀態以供模擬
+// 格式與遊戲狀態儲存到 Redis 與資料庫時相同；模擬不需要動作記錄，因此快照不包含動作記錄
+func snapshotGameState(gameState *models.GameState) ([]byte, error) {
+	withoutLog := *gameState
+	withoutLog.ActionLog = nil
+	return json.Marshal(&withoutLog)
+}
+
+// candidateActions 根據遊戲狀態列出候選動作
+// 候選動作不一定合法，由 LegalActions 實際執行過濾
+func (e *gameEngine) candidateActions(gameState *models.GameState, playerID uuid.UUID) []LegalAction {
+	candidates := []LegalAction{{ActionType: models.ActionTypeSurrender}}
+
+	if len(gameState.PendingDecisions) > 0 {
+		return append(candidates, decisionCandidates(gameState.PendingDecisions[0])...)
+	}
+
+	if gameState.PendingAttack != nil {
+		candidates = append(candidates, LegalAction{ActionType: models.ActionTypeBlock})
+		for _, blockerID := range e.getEligibleBlockers(gameState, gameState.Players[playerID]) {
+			cardID := blockerID
+			candidates = append(candidates, LegalAction{
+				ActionType: models.ActionTypeBlock,
+				ActionData: models.ActionData{CardID: &cardID},
+			})
+		}
+		return candidates
+	}
+
+	candidates = append(candidates,
+		LegalAction{ActionType: models.ActionTypeDrawCard},
+		LegalAction{ActionType: models.ActionTypeExtraDraw},
+		LegalAction{ActionType: models.ActionTypeEndPhase},
+		LegalAction{ActionType: models.ActionTypeEndTurn},
+	)
+
+	player := gameState.Players[playerID]
+	candidates = append(candidates, playCardCandidates(player)...)
+	candidates = append(candidates, attackCandidates(gameState, playerID)...)
+	candidates = append(candidates, moveCandidates(player)...)
+	return candidates
+}
+
+// decisionCandidates 列出回應待決選擇的候選動作
+// 預設選項排在最前面，其後為選項中數量介於最少與最多之間的每一種選法；觸發順序的選擇與順序有關，列出每一種排列
+// 選法超過 maxDecisionCandidates 時只列出前面的選法
+func decisionCandidates(decision models.PendingDecision) []LegalAction {
+	decisionID := decision.ID
+	ordered := decision.Type == models.DecisionTypeTriggerOrder
+	defaultKey := choicesKey(decision.DefaultChoices, ordered)
+
+	candidates := []LegalAction{{
+		ActionType: models.ActionTypeResolveDecision,
+		ActionData: models.ActionData{DecisionID: &decisionID, Choices: decision.DefaultChoices},
+	}}
+
+	used := make([]bool, len(decision.Options))
+	var choose func(chosen []string, start int)
+	choose = func(chosen []string, start int) {
+		if len(candidates) >= maxDecisionCandidates {
+			return
+		}
+		if len(chosen) >= decision.MinChoices && choicesKey(chosen, ordered) != defaultKey {
+			candidates = append(candidates, LegalAction{
+				ActionType: models.ActionTypeResolveDecision,
+				ActionData: models.ActionData{DecisionID: &decisionID, Choices: append([]string(nil), chosen...)},
+			})
+		}
+		if len(chosen) >= decision.MaxChoices {
+			return
+		}
+
+		for i := start; i < len(decision.Options); i++ {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			next := i + 1
+			if ordered {
+				next = 0
+			}
+			choose(append(chosen, decision.Options[i].ID), next)
+			used[i] = false
+		}
+	}
+	choose(nil, 0)
+
+	return candidates
+}
+
+// choicesKey 返回選擇的比較鍵，與順序無關的選擇先排序
+func choicesKey(choices []string, ordered bool) string {
+	if !ordered {
+		choices = append([]string(nil), choices...)
+		sort.Strings(choices)
+	}
+	return strings.Join(choices, "\x00")
+}
+
+// playCardCandidates 列出手牌登場的候選動作
+// 角色卡列出前線與能源線的每個位置以及每個可能的突襲對象，場域卡與事件卡不需指定位置
+func playCardCandidates(player *models.Player) []LegalAction {
+	candidates := []LegalAction{}
+	for _, card := range player.Hand {
+		cardID := card.ID
+
+		if card.CardType != models.CardTypeCharacter {
+			candidates = append(candidates, LegalAction{
+				ActionType: models.ActionTypePlayCard,
+				ActionData: models.ActionData{CardID: &cardID},
+			})
+			continue
+		}
+
+		for _, zone := range []string{"front_line", "energy_line"} {
+			for slot := 0; slot < lineCapacity; slot++ {
+				candidates = append(candidates, LegalAction{
+					ActionType: models.ActionTypePlayCard,
+					ActionData: models.ActionData{CardID: &cardID, Position: &models.Position{Zone: zone, Slot: slot}},
+				})
+			}
+		}
+
+		if !hasKeyword(card, models.KeywordRaid) {
+			continue
+		}
+		for _, line := range [][]models.CardInPlay{player.Board.FrontLine, player.Board.EnergyLine} {
+			for _, base := range line {
+				baseID := base.Card.ID
+				candidates = append(candidates, LegalAction{
+					ActionType: models.ActionTypePlayCard,
+					ActionData: models.ActionData{CardID: &cardID, RaidTargetID: &baseID},
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+// attackCandidates 列出前線角色攻擊的候選動作
+// 每個角色可以攻擊對手玩家或對手前線的每個角色
+func attackCandidates(gameState *models.GameState, playerID uuid.UUID) []LegalAction {
+	candidates := []LegalAction{}
+	opponentTargets := []uuid.UUID{}
+	for id, player := range gameState.Players {
+		if id == playerID {
+			continue
+		}
+		for _, character := range player.Board.FrontLine {
+			opponentTargets = append(opponentTargets, character.Card.ID)
+		}
+	}
+
+	for _, attacker := range gameState.Players[playerID].Board.FrontLine {
+		cardID := attacker.Card.ID
+		candidates = append(candidates, LegalAction{
+			ActionType: models.ActionTypeAttack,
+			ActionData: models.ActionData{CardID: &cardID, TargetType: "player"},
+		})
+		for _, target := range opponentTargets {
+			targetID := target
+			candidates = append(candidates, LegalAction{
+				ActionType: models.ActionTypeAttack,
+				ActionData: models.ActionData{CardID: &cardID, TargetType: "character", TargetID: &targetID},
+			})
+		}
+	}
+	return candidates
+}
+
+// moveCandidates 列出移動階段移動角色的候選動作
+// 目的地有空位時列出每個位置，已滿時列出放置到移除區的每張卡片
+func moveCandidates(player *models.Player) []LegalAction {
+	candidates := []LegalAction{}
+	moves := []struct {
+		from []models.CardInPlay
+		to   []models.CardInPlay
+		zone string
+	}{
+		{player.Board.EnergyLine, player.Board.FrontLine, "front_line"},
+		{player.Board.FrontLine, player.Board.EnergyLine, "energy_line"},
+	}
+
+	for _, move := range moves {
+		for _, character := range move.from {
+			cardID := character.Card.ID
+
+			if len(move.to) >= lineCapacity {
+				for _, replaced := range move.to {
+					replacedID := replaced.Card.ID
+					candidates = append(candidates, LegalAction{
+						ActionType: models.ActionTypeMoveCharacter,
+						ActionData: models.ActionData{
+							CardID:   &cardID,
+							TargetID: &replacedID,
+							Position: &models.Position{Zone: move.zone, Slot: replaced.Position.Slot},
+						},
+					})
+				}
+				continue
+			}
+
+			for slot := 0; slot < lineCapacity; slot++ {
+				candidates = append(candidates, LegalAction{
+					ActionType: models.ActionTypeMoveCharacter,
+					ActionData: models.ActionData{CardID: &cardID, Position: &models.Position{Zone: move.zone, Slot: slot}},
+				})
+			}
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,201 @@
+package engine
+
+import (
+	"fmt"
+
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// EffectiveBP 計算角色目前的BP
+// 基礎BP加上所有生效中的BP修正，非角色卡返回0
+func EffectiveBP(card *models.CardInPlay, gameState *models.GameState) int {
+	if card.Card.BP == nil {
+		return 0
+	}
+
+	bp := *card.Card.BP
+	for _, modifier := range activeModifiers(card, gameState, models.ModifierTypeBP) {
+		if value, ok := modifierValue(modifier.Value); ok {
+			bp += value
+		}
+	}
+	return bp
+}
+
+// activeModifiers 獲取角色身上指定類型且生效中的修正
+// 「產生修正的卡片在場上期間」的修正在來源離場後立即不再生效，即使尚未被移除
+func activeModifiers(card *models.CardInPlay, gameState *models.GameState, modifierType string) []models.CardModifier {
+	modifiers := []models.CardModifier{}
+	for _, modifier := range card.Modifiers {
+		if modifier.Type != modifierType {
+			continue
+		}
+		if modifier.Scope == models.ModifierScopeWhileSourceOnBoard && !cardOnAnyBoard(gameState, modifier.Source) {
+			continue
+		}
+		modifiers = append(modifiers, modifier)
+	}
+	return modifiers
+}
+
+// hasModifier 判斷角色身上是否有指定類型且生效中的修正
+func hasModifier(card *models.CardInPlay, gameState *models.GameState, modifierType string) bool {
+	return len(activeModifiers(card, gameState, modifierType)) > 0
+}
+
+// canAttack 判斷角色是否可以宣告攻擊
+// 角色必須是活動狀態、可以攻擊，且沒有不能攻擊的修正
+func canAttack(card *models.CardInPlay, gameState *models.GameState) bool {
+	return card.Status.IsActive && card.Status.CanAttack && !hasModifier(card, gameState, models.ModifierTypeCannotAttack)
+}
+
+// canBlock 判斷角色是否可以宣告防禦
+// 角色必須是活動狀態、可以防禦，且沒有不能防禦的修正
+func canBlock(card *models.CardInPlay, gameState *models.GameState) bool {
+	return card.Status.IsActive && card.Status.CanBlock && !hasModifier(card, gameState, models.ModifierTypeCannotBlock)
+}
+
+// modifierValue 讀取BP修正的數值
+// 修正器從 Redis 或資料庫讀回時數值會是 float64
+func modifierValue(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// cardOnAnyBoard 判斷卡片是否在任一玩家的前線或能源線上
+func cardOnAnyBoard(gameState *models.GameState, cardID uuid.UUID) bool {
+	for _, player := range gameState.Players {
+		if findCardOnBoard(player, cardID) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// modifierExpired 判斷修正在目前的結束階段是否失效
+func modifierExpired(modifier models.CardModifier, gameState *models.GameState) bool {
+	switch modifier.Scope {
+	case models.ModifierScopeThisTurn:
+		return true
+	case models.ModifierScopeOpponentNextTurn:
+		return gameState.ActivePlayer != modifier.Controller && gameState.Turn > modifier.AppliedAt
+	case models.ModifierScopeWhileSourceOnBoard:
+		return !cardOnAnyBoard(gameState, modifier.Source)
+	default:
+		return false
+	}
+}
+
+// expireModifiers 移除雙方場上角色已失效的修正
+// 於每個結束階段呼叫，無論修正屬於回合玩家或對手
+func expireModifiers(gameState *models.GameState) {
+	expired := 0
+	for _, player := range gameState.Players {
+		for _, line := range [][]models.CardInPlay{player.Board.FrontLine, player.Board.EnergyLine} {
+			for i := range line {
+				remaining := line[i].Modifiers[:0]
+				for _, modifier := range line[i].Modifiers {
+					if modifierExpired(modifier, gameState) {
+						expired++
+						continue
+					}
+					remaining = append(remaining, modifier)
+				}
+				line[i].Modifiers = remaining
+			}
+		}
+	}
+
+	if expired > 0 {
+		logger.Debug("Modifiers expired",
+			zap.Int("turn", gameState.Turn),
+			zap.Int("count", expired))
+	}
+}
+
+// activateCharacters 將回合玩家前線的角色轉為活動狀態
+// 持有「下一次起始階段不轉為活動狀態」修正的角色維持休息狀態，並移除該修正
+func activateCharacters(player *models.Player) {
+	for i := range player.Board.FrontLine {
+		card := &player.Board.FrontLine[i]
+
+		if consumeModifier(card, models.ModifierTypeNoActivate) {
+			continue
+		}
+
+		card.Status.CanAttack = true
+		card.Status.IsActive = true
+		card.Status.IsRested = false
+		card.Status.CanAct = true
+	}
+}
+
+// consumeModifier 移除角色身上所有指定類型的修正，返回是否有修正被移除
+func consumeModifier(card *models.CardInPlay, modifierType string) bool {
+	consumed := false
+	remaining := card.Modifiers[:0]
+	for _, modifier := range card.Modifiers {
+		if modifier.Type == modifierType {
+			consumed = true
+			continue
+		}
+		remaining = append(remaining, modifier)
+	}
+	card.Modifiers = remaining
+	return consumed
+}
+
+// parseModifierScope 讀取效果指定的持續範圍
+// 優先使用 action.scope，其次將舊格式的 action.duration 轉換為持續範圍（1為本回合、2以上為直到對手的下個回合結束、負數為直到離場）
+func parseModifierScope(effect *models.CardEffect, defaultScope string) (string, error) {
+	if scope, exists := effect.Action["scope"].(string); exists {
+		switch scope {
+		case models.ModifierScopeThisTurn, models.ModifierScopeOpponentNextTurn,
+			models.ModifierScopeWhileSourceOnBoard, models.ModifierScopeWhileOnBoard:
+			return scope, nil
+		default:
+			return "", fmt.Errorf("unknown modifier scope: %s", scope)
+		}
+	}
+
+	if duration, exists := effect.Action["duration"].(float64); exists {
+		switch {
+		case duration < 0:
+			return models.ModifierScopeWhileOnBoard, nil
+		case duration <= 1:
+			return models.ModifierScopeThisTurn, nil
+		default:
+			return models.ModifierScopeOpponentNextTurn, nil
+		}
+	}
+
+	return defaultScope, nil
+}
+
+// findCardController 尋找卡片目前所屬的玩家
+// 依序在場上、手牌與其他區域中尋找，找不到時視為回合玩家
+func findCardController(gameState *models.GameState, cardID uuid.UUID) uuid.UUID {
+	for playerID, player := range gameState.Players {
+		if findCardOnBoard(player, cardID) != nil {
+			return playerID
+		}
+		for _, pile := range [][]models.Card{player.Hand, player.Board.PublicArea, player.Board.OutsideArea, player.Board.Graveyard, player.Board.RemoveArea} {
+			for _, card := range pile {
+				if card.ID == cardID {
+					return playerID
+				}
+			}
+		}
+	}
+	return gameState.ActivePlayer
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"fmt"
+
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// findRaidBase 尋找突襲的疊加對象
+// 突襲角色必須持有レイド；對象必須是自己場上未持有レイド的角色，且與突襲角色同名或擁有相同特徵
+func findRaidBase(player *models.Player, raider models.Card, baseID uuid.UUID) (*models.CardInPlay, error) {
+	if raider.CardType != models.CardTypeCharacter || !hasKeyword(raider, models.KeywordRaid) {
+		return nil, fmt.Errorf("only characters with レイド can raid")
+	}
+
+	base := findCardOnBoard(player, baseID)
+	if base == nil {
+		return nil, fmt.Errorf("raid target not found on your board")
+	}
+
+	if base.Card.CardType != models.CardTypeCharacter {
+		return nil, fmt.Errorf("raid target must be a character")
+	}
+
+	if hasKeyword(base.Card, models.KeywordRaid) {
+		return nil, fmt.Errorf("cannot raid onto a character with レイド")
+	}
+
+	if base.Card.Name != raider.Name && !sharesCharacteristic(base.Card, raider) {
+		return nil, fmt.Errorf("raid target must share a name or characteristic with %s", raider.Name)
+	}
+
+	return base, nil
+}
+
+// sharesCharacteristic 檢查兩張卡片是否擁有相同的特徵
+func sharesCharacteristic(a, b models.Card) bool {
+	for _, x := range a.Characteristics {
+		for _, y := range b.Characteristics {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// raidOnto 以突襲方式登場
+// 突襲角色繼承原角色的區域、位置與活動或休息狀態，原角色連同其下方的卡片放入疊加區並失去效果
+func (e *gameEngine) raidOnto(gameState *models.GameState, raider models.Card, base *models.CardInPlay, result *ActionResult) {
+	baseCard := base.Card
+	wasRested := base.Status.IsRested
+
+	stack := make([]models.Card, 0, len(base.Stack)+1)
+	stack = append(stack, base.Stack...)
+	stack = append(stack, base.Card)
+
+	*base = models.CardInPlay{
+		Card:     raider,
+		Position: base.Position,
+		Status: models.CardStatus{
+			IsActive:  !wasRested,
+			IsRested:  wasRested,
+			CanAttack: base.Position.Zone == "front_line" && !wasRested,
+			CanBlock:  true,
+			CanAct:    !wasRested,
+		},
+		Modifiers:  []models.CardModifier{},
+		Owner:      base.Owner,
+		PlayedTurn: gameState.Turn,
+		Stack:      stack,
+	}
+
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:   "CARD_RAIDED",
+		Source: &raider.ID,
+		Target: &baseCard.ID,
+		Data: map[string]interface{}{
+			"zone":        base.Position.Zone,
+			"slot":        base.Position.Slot,
+			"base_rested": wasRested,
+			"stack_size":  len(stack),
+		},
+		Timestamp: e.clock.Now(),
+	})
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// MulliganActionData 調度手牌在動作記錄中的資料
+type MulliganActionData struct {
+	Mulligan bool `json:"mulligan"` // true=調度，false=不調度
+}
+
+// ReplayStep 重播的一個步驟：一個記錄的動作與其處理結果
+type ReplayStep struct {
+	Action          models.GameAction `json:"action"`
+	GameState       *models.GameState `json:"game_state"`
+	Effects         []EffectResult    `json:"effects"`
+	EventsTriggered []GameEvent       `json:"events_triggered"`
+}
+
+// Replay 以獨立的引擎實例逐步重播遊戲，不影響執行中的遊戲
+// 動作需依序號排列，且每個動作完成後的狀態版本必須等於其序號，否則表示記錄不完整或與引擎規則不符
+type Replay struct {
+	gameID  uuid.UUID
+	engine  GameEngine
+	clock   *ManualClock
+	actions []models.GameAction
+	next    int
+	state   *models.GameState
+}
+
+// NewReplay 以初始設定建立重播，初始設定必須指定種子
+// 建立後的狀態為第0步（發完起始手牌、尚未調度），使用完畢後需呼叫 Close
+func NewReplay(ctx context.Context, setup *InitGameRequest, actions []models.GameAction) (*Replay, error) {
+	if setup.Seed == nil {
+		return nil, fmt.Errorf("game setup has no seed")
+	}
+
+	clock := &ManualClock{}
+	if len(actions) > 0 {
+		clock.Set(actions[0].Timestamp)
+	}
+	replayEngine := NewGameEngine(nil, clock)
+
+	gameState, err := replayEngine.InitializeGame(ctx, setup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize game for replay: %w", err)
+	}
+
+	return &Replay{
+		gameID:  setup.GameID,
+		engine:  replayEngine,
+		clock:   clock,
+		actions: actions,
+		state:   gameState,
+	}, nil
+}
+
+// State 返回目前步驟的遊戲狀態
+func (r *Replay) State() *models.GameState {
+	return r.state
+}
+
+// Step 返回已重播的動作數量
+func (r *Replay) Step() int {
+	return r.next
+}
+
+// Done 返回是否已重播所有動作
+func (r *Replay) Done() bool {
+	return r.next >= len(r.actions)
+}
+
+// Next 重播下一個動作並返回其結果
+func (r *Replay) Next(ctx context.Context) (*ReplayStep, error) {
+	if r.Done() {
+		return nil, fmt.Errorf("replay has no more actions")
+	}
+
+	action := r.actions[r.next]
+	r.clock.Set(action.Timestamp)
+
+	step := &ReplayStep{Effects: []EffectResult{}, EventsTriggered: []GameEvent{}}
+	switch action.ActionType {
+	case models.ActionTypeMulligan:
+		var data MulliganActionData
+		if err := json.Unmarshal(action.ActionData, &data); err != nil {
+			return nil, fmt.Errorf("invalid mulligan data at sequence %d: %w", action.SequenceNumber, err)
+		}
+		gameState, err := r.engine.PerformMulligan(ctx, &MulliganRequest{
+			GameID:   r.gameID,
+			PlayerID: action.PlayerID,
+			Mulligan: data.Mulligan,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mulligan at sequence %d failed on replay: %w", action.SequenceNumber, err)
+		}
+		step.GameState = gameState
+	default:
+		result, err := r.engine.ProcessAction(ctx, r.gameID, &action)
+		if err != nil {
+			return nil, fmt.Errorf("action at sequence %d failed on replay: %w", action.SequenceNumber, err)
+		}
+		if !result.Success {
+			return nil, fmt.Errorf("action at sequence %d (%s) failed on replay: %s", action.SequenceNumber, action.ActionType, result.Error)
+		}
+		step.GameState = result.GameState
+		step.Effects = result.Effects
+		step.EventsTriggered = result.EventsTriggered
+	}
+
+	if step.GameState.Version != action.SequenceNumber {
+		return nil, fmt.Errorf("replay diverged at sequence %d: state version is %d", action.SequenceNumber, step.GameState.Version)
+	}
+
+	step.Action = action
+	r.state = step.GameState
+	r.next++
+	return step, nil
+}
+
+// Close 釋放重播使用的遊戲
+func (r *Replay) Close(ctx context.Context) {
+	r.engine.UnloadGame(ctx, r.gameID)
+}
+
+// ReplayGame 以初始設定與動作記錄重建遊戲狀態
+// 依序重播所有動作並返回最後的狀態
+func ReplayGame(ctx context.Context, setup *InitGameRequest, actions []models.GameAction) (*models.GameState, error) {
+	replay, err := NewReplay(ctx, setup, actions)
+	if err != nil {
+		return nil, err
+	}
+	defer replay.Close(ctx)
+
+	for !replay.Done() {
+		if _, err := replay.Next(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return replay.State(), nil
+}
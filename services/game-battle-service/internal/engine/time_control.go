@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ExpiredClock 時間已用完的計時玩家
+type ExpiredClock struct {
+	GameID   uuid.UUID `json:"game_id"`
+	PlayerID uuid.UUID `json:"player_id"`
+}
+
+// ExpiredDecision 超過期限仍未做出的待決選擇
+type ExpiredDecision struct {
+	GameID     uuid.UUID `json:"game_id"`
+	PlayerID   uuid.UUID `json:"player_id"`
+	DecisionID uuid.UUID `json:"decision_id"`
+	Choices    []string  `json:"choices"` // 選擇的預設選項
+}
+
+// newClocks 依時間限制建立雙方玩家的時鐘，回合時間在回合開始時才給予
+func newClocks(timeControl *models.TimeControl, playerIDs ...uuid.UUID) map[uuid.UUID]*models.PlayerClock {
+	clocks := make(map[uuid.UUID]*models.PlayerClock, len(playerIDs))
+	for _, playerID := range playerIDs {
+		clocks[playerID] = &models.PlayerClock{
+			ReserveRemainingMs: int64(timeControl.ReserveSeconds) * 1000,
+		}
+	}
+	return clocks
+}
+
+// playerToAct 返回目前需要行動的玩家
+// 有待決選擇時為做出選擇的玩家，防禦窗口開啟時為防禦方，否則為主動玩家
+func playerToAct(gameState *models.GameState) uuid.UUID {
+	if len(gameState.PendingDecisions) > 0 {
+		return gameState.PendingDecisions[0].PlayerID
+	}
+	if gameState.PendingAttack != nil {
+		return gameState.PendingAttack.DefendingPlayer
+	}
+	return gameState.ActivePlayer
+}
+
+// startClock 在第一回合開始時啟動時鐘
+func startClock(gameState *models.GameState, now time.Time) {
+	if gameState.TimeControl == nil {
+		return
+	}
+	resetTurnClocks(gameState)
+	setClockPlayer(gameState, now)
+}
+
+// runClock 在動作成功後更新時鐘
+// 將經過的時間計入原本的計時玩家，換回合時重置回合時間，再由需要行動的玩家開始計時
+func runClock(gameState *models.GameState, turnBefore int, now time.Time) {
+	if gameState.TimeControl == nil || gameState.ClockPlayer == nil {
+		return
+	}
+	chargeClock(gameState, now)
+	if gameState.Turn != turnBefore {
+		resetTurnClocks(gameState)
+	}
+	setClockPlayer(gameState, now)
+}
+
+// stopClock 遊戲結束時停止計時
+func stopClock(gameState *models.GameState) {
+	gameState.ClockPlayer = nil
+	gameState.ClockStartedAt = nil
+}
+
+// resetTurnClocks 新回合開始時給予主動玩家回合時間
+// 非主動玩家在對手回合中回應時只能使用備用時間
+func resetTurnClocks(gameState *models.GameState) {
+	for playerID, clock := range gameState.Clocks {
+		if playerID == gameState.ActivePlayer {
+			clock.TurnRemainingMs = int64(gameState.TimeControl.TurnSeconds) * 1000
+		} else {
+			clock.TurnRemainingMs = 0
+		}
+	}
+}
+
+// setClockPlayer 由需要行動的玩家從 now 開始計時
+func setClockPlayer(gameState *models.GameState, now time.Time) {
+	playerID := playerToAct(gameState)
+	gameState.ClockPlayer = &playerID
+	gameState.ClockStartedAt = &now
+}
+
+// chargeClock 將計時開始到 now 經過的時間計入計時玩家，先使用回合時間，用完後使用備用時間
+func chargeClock(gameState *models.GameState, now time.Time) {
+	clock := gameState.Clocks[*gameState.ClockPlayer]
+	if clock == nil || gameState.ClockStartedAt == nil {
+		return
+	}
+
+	elapsed := max(now.Sub(*gameState.ClockStartedAt).Milliseconds(), 0)
+	fromTurn := min(elapsed, clock.TurnRemainingMs)
+	clock.TurnRemainingMs -= fromTurn
+	clock.ReserveRemainingMs = max(clock.ReserveRemainingMs-(elapsed-fromTurn), 0)
+}
+
+// clockRemaining 返回計時玩家在 now 時剩餘的總時間（毫秒），沒有玩家計時時返回false
+func clockRemaining(gameState *models.GameState, now time.Time) (int64, bool) {
+	if gameState.TimeControl == nil || gameState.ClockPlayer == nil || gameState.ClockStartedAt == nil {
+		return 0, false
+	}
+	clock := gameState.Clocks[*gameState.ClockPlayer]
+	if clock == nil {
+		return 0, false
+	}
+
+	elapsed := now.Sub(*gameState.ClockStartedAt).Milliseconds()
+	return clock.TurnRemainingMs + clock.ReserveRemainingMs - elapsed, true
+}
+
+// validateTimeout 驗證超時動作
+// 只有計時玩家的回合時間與備用時間都用完時才能送出，判斷時間以動作的時間戳為準，重播時結果相同
+func (e *gameEngine) validateTimeout(gameState *models.GameState, action *models.GameAction) error {
+	if gameState.TimeControl == nil {
+		return fmt.Errorf("game has no time control")
+	}
+	if gameState.ClockPlayer == nil || *gameState.ClockPlayer != action.PlayerID {
+		return fmt.Errorf("player clock is not running")
+	}
+	if remaining, _ := clockRemaining(gameState, action.Timestamp); remaining > 0 {
+		return fmt.Errorf("player still has time left")
+	}
+	return nil
+}
+
+// processTimeout 處理超時
+// 記錄超時次數，達到上限時判負；否則以預設選項代為完成玩家的待決選擇、放棄防禦，輪到玩家的回合時結束回合
+func (e *gameEngine) processTimeout(gameState *models.GameState, action *models.GameAction, result *ActionResult) {
+	playerID := action.PlayerID
+	clock := gameState.Clocks[playerID]
+	clock.Timeouts++
+
+	result.EventsTriggered = append(result.EventsTriggered, GameEvent{
+		Type:      "TIMEOUT",
+		Source:    &playerID,
+		Data:      map[string]interface{}{"timeouts": clock.Timeouts, "max_timeouts": gameState.TimeControl.MaxTimeouts},
+		Timestamp: e.clock.Now(),
+	})
+
+	logger.Debug("Player timed out",
+		zap.String("player", playerID.String()),
+		zap.Int("timeouts", clock.Timeouts))
+
+	if gameState.TimeControl.MaxTimeouts > 0 && clock.Timeouts >= gameState.TimeControl.MaxTimeouts {
+		gameState.TimedOut = &playerID
+		return
+	}
+
+	turnEnded := false
+	for {
+		switch {
+		case len(gameState.PendingDecisions) > 0:
+			decision := gameState.PendingDecisions[0]
+			if decision.PlayerID != playerID {
+				return
+			}
+			e.resolveDecision(gameState, &decision, decision.DefaultChoices, result)
+			if !result.Success {
+				return
+			}
+			e.resolveTriggers(gameState, result)
+			// 結束回合途中需要的選擇都解決後，繼續結束回合
+			if gameState.EndingTurn && len(gameState.PendingDecisions) == 0 {
+				e.finishTurn(gameState, result)
+			}
+		case gameState.PendingAttack != nil:
+			if gameState.PendingAttack.DefendingPlayer != playerID {
+				return
+			}
+			e.processBlock(gameState, &models.GameAction{
+				PlayerID:   playerID,
+				ActionType: models.ActionTypeBlock,
+				ActionData: json.RawMessage("{}"),
+			}, result)
+			if !result.Success {
+				return
+			}
+			e.resolveTriggers(gameState, result)
+		case gameState.ActivePlayer == playerID && !turnEnded:
+			e.finishTurn(gameState, result)
+			turnEnded = true
+		default:
+			return
+		}
+	}
+}
+
+// ExpiredClocks 返回記憶體中計時玩家時間已用完的遊戲
+// 由排程定期呼叫，對每個結果送出超時動作
+func (e *gameEngine) ExpiredClocks(ctx context.Context) []ExpiredClock {
+	now := e.clock.Now()
+	var expired []ExpiredClock
+	for _, actor := range e.games.list() {
+		err := actor.do(ctx, func(gameState *models.GameState) error {
+			if remaining, running := clockRemaining(gameState, now); running && remaining <= 0 {
+				expired = append(expired, ExpiredClock{GameID: actor.gameID, PlayerID: *gameState.ClockPlayer})
+			}
+			return nil
+		})
+		if err != nil && ctx.Err() != nil {
+			break
+		}
+	}
+	return expired
+}
+
+// ExpiredDecisions 返回記憶體中當前待決選擇已超過期限的遊戲
+// 不論遊戲是否計時都會檢查，由排程定期呼叫，以預設選項代為回應每個結果
+func (e *gameEngine) ExpiredDecisions(ctx context.Context) []ExpiredDecision {
+	now := e.clock.Now()
+	var expired []ExpiredDecision
+	for _, actor := range e.games.list() {
+		err := actor.do(ctx, func(gameState *models.GameState) error {
+			if len(gameState.PendingDecisions) == 0 {
+				return nil
+			}
+			decision := gameState.PendingDecisions[0]
+			if now.Before(decision.Deadline) {
+				return nil
+			}
+			expired = append(expired, ExpiredDecision{
+				GameID:     actor.gameID,
+				PlayerID:   decision.PlayerID,
+				DecisionID: decision.ID,
+				Choices:    append([]string(nil), decision.DefaultChoices...),
+			})
+			return nil
+		})
+		if err != nil && ctx.Err() != nil {
+			break
+		}
+	}
+	return expired
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// ViewGameState 返回指定觀看者可見的遊戲狀態
+// 觀看者只看得到自己的手牌與隱藏區域；對手的手牌、雙方生命區與對手的隱藏區域以背面朝上的卡片代替，雙方卡組只提供張數
+// 觀看者不是遊戲中的玩家時（例如 uuid.Nil）返回只含公開資訊的狀態；傳入的狀態不會被修改
+func ViewGameState(gameState *models.GameState, viewerID uuid.UUID) *models.GameState {
+	if gameState == nil {
+		return nil
+	}
+
+	view := *gameState
+	// 種子與洗牌次數可推算出卡組順序
+	view.Seed = 0
+
+	view.Players = make(map[uuid.UUID]*models.Player, len(gameState.Players))
+	for playerID, player := range gameState.Players {
+		view.Players[playerID] = viewPlayer(player, playerID == viewerID)
+	}
+
+	view.PendingDecisions = make([]models.PendingDecision, 0, len(gameState.PendingDecisions))
+	for _, decision := range gameState.PendingDecisions {
+		if decision.PlayerID != viewerID {
+			// 選項可能包含手牌內容，只有做出選擇的玩家看得到
+			decision.Options = []models.DecisionOption{}
+			decision.DefaultChoices = []string{}
+			decision.Context = nil
+		}
+		view.PendingDecisions = append(view.PendingDecisions, decision)
+	}
+
+	return &view
+}
+
+// viewPlayer 返回玩家在觀看者眼中的樣子
+// isViewer 為 true 時保留玩家自己的手牌與隱藏區域；卡組順序與生命區連玩家自己也看不到
+func viewPlayer(player *models.Player, isViewer bool) *models.Player {
+	view := *player
+
+	view.DeckCount = len(player.Deck)
+	view.Deck = []models.Card{}
+	view.Board.LifeArea = faceDownCards(len(player.Board.LifeArea))
+
+	if !isViewer {
+		view.Hand = faceDownCards(len(player.Hand))
+		view.Board.HiddenArea = faceDownCards(len(player.Board.HiddenArea))
+	}
+
+	return &view
+}
+
+// faceDownCards 返回指定數量的背面朝上卡片，卡片不含任何資訊
+func faceDownCards(count int) []models.Card {
+	return make([]models.Card, count)
+}
@@ -1,21 +1,28 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"ua/services/game-battle-service/internal/service"
 	"ua/shared/utils"
+	"ua/shared/websocket"
 )
 
 type GameHandler struct {
 	gameService service.GameService
+	hub         *websocket.Hub
 }
 
-func NewGameHandler(gameService service.GameService) *GameHandler {
+func NewGameHandler(gameService service.GameService, hub *websocket.Hub) *GameHandler {
 	return &GameHandler{
 		gameService: gameService,
+		hub:         hub,
 	}
 }
 
@@ -38,6 +45,10 @@ func (h *GameHandler) CreateGame(c *gin.Context) {
 
 	response, err := h.gameService.CreateGame(c.Request.Context(), &req)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid game mode") {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to create game: "+err.Error())
 		return
 	}
@@ -108,6 +119,7 @@ func (h *GameHandler) JoinGame(c *gin.Context) {
 // @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /games/{gameId}/mulligan [post]
 // @Security BearerAuth
@@ -149,6 +161,10 @@ func (h *GameHandler) PerformMulligan(c *gin.Context) {
 			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
 			return
 		}
+		if errors.Is(err, service.ErrStateConflict) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to perform mulligan: "+err.Error())
 		return
 	}
@@ -168,6 +184,7 @@ func (h *GameHandler) PerformMulligan(c *gin.Context) {
 // @Failure 400 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /games/{gameId}/actions [post]
 // @Security BearerAuth
@@ -219,6 +236,14 @@ func (h *GameHandler) PlayAction(c *gin.Context) {
 			utils.ErrorResponse(c, http.StatusForbidden, "Player not part of this game")
 			return
 		}
+		if err.Error() == "game is not in progress" {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrStateConflict) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to play action: "+err.Error())
 		return
 	}
@@ -334,6 +359,162 @@ func (h *GameHandler) GetTurnInfo(c *gin.Context) {
 	utils.SuccessResponse(c, turnInfo)
 }
 
+// @Summary Get legal actions
+// @Description List every action the current player is allowed to take right now. Each entry can be sent as-is to the actions endpoint
+// @Tags games
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param Authorization header string false "Bearer token (optional, can use global auth instead)"
+// @Success 200 {object} utils.Response{data=service.LegalActionsResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /games/{gameId}/legal-actions [get]
+// @Security BearerAuth
+func (h *GameHandler) GetLegalActions(c *gin.Context) {
+	gameIDStr := c.Param("gameId")
+	gameID, err := uuid.Parse(gameIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid game ID")
+		return
+	}
+
+	playerIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	playerID, ok := playerIDInterface.(uuid.UUID)
+	if !ok {
+		utils.InternalServerErrorResponse(c, "Invalid player ID format")
+		return
+	}
+
+	response, err := h.gameService.GetLegalActions(c.Request.Context(), gameID, playerID)
+	if err != nil {
+		if err.Error() == "game not found" {
+			utils.NotFoundResponse(c, "Game not found")
+			return
+		}
+		if err.Error() == "player not part of this game" {
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get legal actions: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, response)
+}
+
+// @Summary Get replay step
+// @Description Get a finished game as it was after the given number of recorded actions, with the action, effects and events of that step. Step 0 is the dealt opening hands
+// @Tags games
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param step query int false "Number of recorded actions to replay (default 0)"
+// @Param Authorization header string false "Bearer token (optional, can use global auth instead)"
+// @Success 200 {object} utils.Response{data=service.ReplayStepResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /games/{gameId}/replay [get]
+// @Security BearerAuth
+func (h *GameHandler) GetReplay(c *gin.Context) {
+	gameIDStr := c.Param("gameId")
+	gameID, err := uuid.Parse(gameIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid game ID")
+		return
+	}
+
+	step, err := strconv.Atoi(c.DefaultQuery("step", "0"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid step")
+		return
+	}
+
+	response, err := h.gameService.GetReplayStep(c.Request.Context(), gameID, step)
+	if err != nil {
+		h.replayError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, response)
+}
+
+// @Summary Stream replay
+// @Description Stream a finished game as server-sent events: one "step" event per recorded action from the given step to the end, then an "end" event
+// @Tags games
+// @Produce text/event-stream
+// @Param gameId path string true "Game ID"
+// @Param from query int false "First step to send (default 0)"
+// @Param Authorization header string false "Bearer token (optional, can use global auth instead)"
+// @Success 200 {object} service.ReplayStepResponse
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /games/{gameId}/replay/stream [get]
+// @Security BearerAuth
+func (h *GameHandler) StreamReplay(c *gin.Context) {
+	gameIDStr := c.Param("gameId")
+	gameID, err := uuid.Parse(gameIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid game ID")
+		return
+	}
+
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid from step")
+		return
+	}
+
+	streaming := false
+	err = h.gameService.StreamReplay(c.Request.Context(), gameID, from, func(step *service.ReplayStepResponse) error {
+		if !streaming {
+			c.Header("Content-Type", "text/event-stream")
+			c.Header("Cache-Control", "no-cache")
+			c.Header("Connection", "keep-alive")
+			streaming = true
+		}
+		c.SSEvent("step", step)
+		c.Writer.Flush()
+		return c.Request.Context().Err()
+	})
+
+	if !streaming {
+		if err != nil {
+			h.replayError(c, err)
+		}
+		return
+	}
+	if err != nil {
+		c.SSEvent("error", err.Error())
+	} else {
+		c.SSEvent("end", gin.H{"game_id": gameID})
+	}
+	c.Writer.Flush()
+}
+
+// replayError maps replay errors to HTTP responses
+func (h *GameHandler) replayError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "game not found":
+		utils.NotFoundResponse(c, "Game not found")
+	case "game is not finished":
+		utils.ErrorResponse(c, http.StatusForbidden, "Replays are available once the game has ended")
+	case "step out of range":
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, "Failed to replay game: "+err.Error())
+	}
+}
+
 // @Summary Get active games
 // @Description Get all active games for the current player
 // @Tags games
@@ -365,6 +546,82 @@ func (h *GameHandler) GetActiveGames(c *gin.Context) {
 	utils.SuccessResponse(c, response)
 }
 
+// @Summary List spectatable games
+// @Description List games in progress that allow spectators, most recently started first, with their spectator counts
+// @Tags spectators
+// @Produce json
+// @Param Authorization header string false "Bearer token (optional, can use global auth instead)"
+// @Success 200 {object} utils.Response{data=service.SpectatableGamesResponse}
+// @Failure 500 {object} utils.Response
+// @Router /games/spectatable [get]
+// @Security BearerAuth
+func (h *GameHandler) GetSpectatableGames(c *gin.Context) {
+	response, err := h.gameService.GetSpectatableGames(c.Request.Context())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get spectatable games: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, response)
+}
+
+// @Summary Spectate game
+// @Description Open a WebSocket that receives the public view of a game. The first message is the current spectator view, followed by a SPECTATOR_UPDATE after every action once the game's spectator delay has passed
+// @Tags spectators
+// @Param gameId path string true "Game ID"
+// @Param token query string false "JWT token"
+// @Success 101 {object} service.SpectatorUpdate
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /ws/games/{gameId}/spectate [get]
+func (h *GameHandler) SpectateGame(c *gin.Context) {
+	gameIDStr := c.Param("gameId")
+	gameID, err := uuid.Parse(gameIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid game ID")
+		return
+	}
+
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.InternalServerErrorResponse(c, "Invalid user ID format")
+		return
+	}
+
+	snapshot, err := h.gameService.GetSpectatorView(c.Request.Context(), gameID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "game not found":
+			utils.NotFoundResponse(c, "Game not found")
+		case "game is not in progress", "game is not open to spectators", "players cannot spectate their own game":
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to spectate game: "+err.Error())
+		}
+		return
+	}
+
+	message, err := json.Marshal(websocket.Message{
+		Type:    service.MessageTypeSpectatorUpdate,
+		Payload: snapshot,
+		GameID:  &gameID,
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to serialize spectator view")
+		return
+	}
+
+	h.hub.HandleSpectator(c, gameID, message)
+}
+
 // @Summary Surrender game
 // @Description Surrender the current game
 // @Tags games
@@ -416,8 +673,8 @@ func (h *GameHandler) SurrenderGame(c *gin.Context) {
 }
 
 type ActionRequest struct {
-	ActionType string `json:"action_type" binding:"required"`
-	ActionData []int  `json:"action_data,omitempty"`
+	ActionType string          `json:"action_type" binding:"required"`
+	ActionData json.RawMessage `json:"action_data,omitempty" swaggertype:"object"`
 }
 
 type MulliganRequest struct {
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ua/services/game-battle-service/internal/service"
+	"ua/shared/models"
+	"ua/shared/websocket"
+
+	"github.com/google/uuid"
+)
+
+// SocketHandler runs the game requests players send over the WebSocket through the same service calls as the REST API.
+// The resulting state reaches both players and the spectators through the usual game updates and patches,
+// so action acks leave it out; join_game and resync acks carry the full state.
+type SocketHandler struct {
+	gameService service.GameService
+}
+
+func NewSocketHandler(gameService service.GameService) *SocketHandler {
+	return &SocketHandler{
+		gameService: gameService,
+	}
+}
+
+func (h *SocketHandler) HandleRequest(ctx context.Context, userID uuid.UUID, request *websocket.Request) (interface{}, error) {
+	gameID := *request.GameID
+
+	switch request.Type {
+	case websocket.RequestJoinGame, websocket.RequestResync:
+		// Only players of the game get a view of it, so this also authorizes joining the game room
+		return h.gameService.GetGame(ctx, gameID, userID)
+
+	case websocket.RequestSubmitAction:
+		var payload websocket.SubmitActionPayload
+		if err := decodePayload(request, &payload); err != nil {
+			return nil, err
+		}
+		if payload.ActionType == "" {
+			return nil, fmt.Errorf("action_type is required")
+		}
+		return withoutState(h.gameService.PlayAction(ctx, &service.PlayActionRequest{
+			GameID:     gameID,
+			PlayerID:   userID,
+			ActionType: payload.ActionType,
+			ActionData: payload.ActionData,
+		}))
+
+	case websocket.RequestMulligan:
+		var payload websocket.MulliganPayload
+		if err := decodePayload(request, &payload); err != nil {
+			return nil, err
+		}
+		response, err := h.gameService.PerformMulligan(ctx, &service.MulliganRequest{
+			GameID:   gameID,
+			PlayerID: userID,
+			Mulligan: payload.Mulligan,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.GameState = nil
+		return response, nil
+
+	case websocket.RequestResolveDecision:
+		var payload websocket.ResolveDecisionPayload
+		if err := decodePayload(request, &payload); err != nil {
+			return nil, err
+		}
+		actionData, err := json.Marshal(models.ActionData{DecisionID: &payload.DecisionID, Choices: payload.Choices})
+		if err != nil {
+			return nil, err
+		}
+		return withoutState(h.gameService.PlayAction(ctx, &service.PlayActionRequest{
+			GameID:     gameID,
+			PlayerID:   userID,
+			ActionType: models.ActionTypeResolveDecision,
+			ActionData: actionData,
+		}))
+
+	default:
+		return nil, fmt.Errorf("unknown message type: %s", request.Type)
+	}
+}
+
+// withoutState drops the game state from an action response; the player already got it pushed
+func withoutState(response *service.ActionResponse, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	response.GameState = nil
+	return response, nil
+}
+
+func decodePayload(request *websocket.Request, payload interface{}) error {
+	if len(request.Payload) == 0 {
+		return fmt.Errorf("payload is required")
+	}
+	if err := json.Unmarshal(request.Payload, payload); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	return nil
+}
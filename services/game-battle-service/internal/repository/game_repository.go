@@ -2,33 +2,99 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
 	"ua/shared/database"
 	"ua/shared/models"
 	"ua/shared/redis"
 )
 
+// ErrVersionConflict 儲存遊戲狀態時，已儲存的版本與預期不符
+// 代表另一個請求或服務實例已先寫入較新的狀態，呼叫者應重新載入遊戲狀態後再重試
+var ErrVersionConflict = errors.New("game state version conflict")
+
+// ErrGameNotInProgress 遊戲已結束，不再接受狀態寫入或再次設定勝者
+// 投降與勝負判定可能同時發生，只有先寫入的一方生效
+var ErrGameNotInProgress = errors.New("game is not in progress")
+
+// saveGameStateScript 以版本比對寫入 Redis 中的遊戲狀態
+// 快取的版本與預期版本不同時不寫入並返回0；沒有快取版本時直接寫入
+// KEYS: state, version, info  ARGV: 預期版本, 新版本, 狀態JSON, TTL秒數, 回合, 階段, 主動玩家, 更新時間
+var saveGameStateScript = goredis.NewScript(`
+local current = redis.call('GET', KEYS[2])
+if current and current ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[3], 'EX', ARGV[4])
+redis.call('SET', KEYS[2], ARGV[2], 'EX', ARGV[4])
+redis.call('HSET', KEYS[3], 'current_turn', ARGV[5], 'phase', ARGV[6], 'active_player', ARGV[7], 'updated_at', ARGV[8])
+redis.call('EXPIRE', KEYS[3], ARGV[4])
+return 1
+`)
+
+// cacheGameStateScript 將從資料庫讀取的遊戲狀態放回 Redis
+// 只在快取不存在或版本較舊時寫入，避免較舊的讀取結果覆蓋剛寫入的新狀態
+// KEYS: state, version  ARGV: 版本, 狀態JSON, TTL秒數
+var cacheGameStateScript = goredis.NewScript(`
+local current = redis.call('GET', KEYS[2])
+if current and tonumber(current) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'EX', ARGV[3])
+redis.call('SET', KEYS[2], ARGV[1], 'EX', ARGV[3])
+return 1
+`)
+
+// gameStateTTL 遊戲狀態在 Redis 中的保存時間
+const gameStateTTL = 24 * time.Hour
+
+// GameSetup 遊戲的初始設定（種子與洗牌前的卡組），與動作記錄一起可重建遊戲的任何時點
+type GameSetup struct {
+	GameID      uuid.UUID
+	Seed        int64
+	Player1ID   uuid.UUID
+	Player2ID   uuid.UUID
+	Player1Deck []models.Card
+	Player2Deck []models.Card
+	TimeControl *models.TimeControl // 遊戲的時間限制，不限時的遊戲為nil
+}
+
 type PlayerJoinStatus struct {
 	Player1Joined bool
 	Player2Joined bool
 }
 
 type GameRepository interface {
-	CreateGame(ctx context.Context, game *models.Game) error
+	CreateGame(ctx context.Context, game *models.Game, setup *GameSetup) error
 	GetGame(ctx context.Context, gameID uuid.UUID) (*models.Game, error)
+	GetGameSetup(ctx context.Context, gameID uuid.UUID) (*GameSetup, error)
 	UpdateGame(ctx context.Context, game *models.Game) error
-	SaveGameState(ctx context.Context, gameID uuid.UUID, gameState *models.GameState) error
+	// SaveGameState 以樂觀鎖在同一交易中寫入遊戲狀態與產生該狀態的動作
+	// 已儲存的版本必須是 gameState.Version-1，否則返回 ErrVersionConflict；遊戲已結束時返回 ErrGameNotInProgress，狀態與動作都不會寫入
+	SaveGameState(ctx context.Context, gameID uuid.UUID, gameState *models.GameState, action *models.GameAction) error
 	LoadGameState(ctx context.Context, gameID uuid.UUID) (*models.GameState, error)
-	AddAction(ctx context.Context, gameID uuid.UUID, action *models.GameAction) error
 	GetActions(ctx context.Context, gameID uuid.UUID, fromIndex int) ([]*models.GameAction, error)
+	// GetActionLog 從資料庫依序號讀取動作記錄，uptoSeq 大於0時只讀取到該序號為止
+	GetActionLog(ctx context.Context, gameID uuid.UUID, uptoSeq int64) ([]models.GameAction, error)
 	UpdateGameStatus(ctx context.Context, gameID uuid.UUID, status models.GameStatus) error
 	GetActiveGames(ctx context.Context, playerID uuid.UUID) ([]*models.Game, error)
-	SetGameWinner(ctx context.Context, gameID uuid.UUID, winner uuid.UUID, reason string) error
+	// SetGameWinner 結束進行中的遊戲並設定勝者與結束原因（models.EndReason*），遊戲已不在進行中時返回 ErrGameNotInProgress
+	// 遊戲結果標記為待回報，直到 MarkResultReported
+	SetGameWinner(ctx context.Context, gameID uuid.UUID, winner uuid.UUID, endReason string) error
+	// GetPendingResults 讀取在 completedBefore 之前結束、結果尚未回報的遊戲，不含遊戲狀態，最早結束的遊戲在前
+	GetPendingResults(ctx context.Context, completedBefore time.Time, limit int) ([]*models.Game, error)
+	// MarkResultReported 將遊戲結果標記為已回報
+	MarkResultReported(ctx context.Context, gameID uuid.UUID) error
 	GetGamesByStatus(ctx context.Context, status models.GameStatus, limit int) ([]*models.Game, error)
+	// GetSpectatableGames 讀取進行中且允許觀戰的遊戲，不含遊戲狀態，最近開始的遊戲在前
+	GetSpectatableGames(ctx context.Context, limit int) ([]*models.Game, error)
 	// Player join status management
 	SetPlayerJoined(ctx context.Context, gameID uuid.UUID, playerID uuid.UUID) error
 	GetPlayerJoinStatus(ctx context.Context, gameID uuid.UUID) (*PlayerJoinStatus, error)
@@ -49,15 +115,34 @@ func NewGameRepository(db *database.DB, redis *redis.Client) GameRepository {
 	}
 }
 
-func (r *gameRepository) CreateGame(ctx context.Context, game *models.Game) error {
+func (r *gameRepository) CreateGame(ctx context.Context, game *models.Game, setup *GameSetup) error {
+	player1DeckJSON, err := json.Marshal(setup.Player1Deck)
+	if err != nil {
+		return fmt.Errorf("failed to marshal player1 deck: %w", err)
+	}
+	player2DeckJSON, err := json.Marshal(setup.Player2Deck)
+	if err != nil {
+		return fmt.Errorf("failed to marshal player2 deck: %w", err)
+	}
+	var timeControlJSON []byte
+	if setup.TimeControl != nil {
+		if timeControlJSON, err = json.Marshal(setup.TimeControl); err != nil {
+			return fmt.Errorf("failed to marshal time control: %w", err)
+		}
+	}
+
 	query := `
 		INSERT INTO games (id, player1_id, player2_id, status, current_turn, phase, 
-						  active_player, game_state, started_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+						  active_player, game_state, seed, player1_deck, player2_deck, time_control,
+						  game_mode, allow_spectators, spectator_delay,
+						  started_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		game.ID, game.Player1ID, game.Player2ID, game.Status,
 		game.CurrentTurn, game.Phase.String(), game.ActivePlayer, game.GameState,
+		setup.Seed, player1DeckJSON, player2DeckJSON, timeControlJSON,
+		game.GameMode, game.AllowSpectators, game.SpectatorDelay,
 		game.StartedAt, game.CreatedAt, game.UpdatedAt)
 
 	if err != nil {
@@ -108,8 +193,8 @@ func (r *gameRepository) CreateGame(ctx context.Context, game *models.Game) erro
 func (r *gameRepository) GetGame(ctx context.Context, gameID uuid.UUID) (*models.Game, error) {
 	query := `
 		SELECT id, player1_id, player2_id, status, current_turn, phase,
-			   active_player, game_state, winner, started_at, completed_at,
-			   created_at, updated_at
+			   active_player, game_state, winner, COALESCE(game_mode, 'RANKED'), allow_spectators,
+			   spectator_delay, started_at, completed_at, created_at, updated_at
 		FROM games WHERE id = $1`
 
 	game := &models.Game{}
@@ -119,7 +204,8 @@ func (r *gameRepository) GetGame(ctx context.Context, gameID uuid.UUID) (*models
 	err := r.db.QueryRowContext(ctx, query, gameID).Scan(
 		&game.ID, &game.Player1ID, &game.Player2ID, &game.Status,
 		&game.CurrentTurn, &phaseStr, &game.ActivePlayer, &gameStateJSON,
-		&game.Winner, &game.StartedAt, &game.CompletedAt,
+		&game.Winner, &game.GameMode, &game.AllowSpectators,
+		&game.SpectatorDelay, &game.StartedAt, &game.CompletedAt,
 		&game.CreatedAt, &game.UpdatedAt)
 
 	if err != nil {
@@ -136,28 +222,59 @@ func (r *gameRepository) GetGame(ctx context.Context, gameID uuid.UUID) (*models
 	return game, nil
 }
 
+// GetGameSetup 讀取遊戲的初始設定
+func (r *gameRepository) GetGameSetup(ctx context.Context, gameID uuid.UUID) (*GameSetup, error) {
+	query := "SELECT player1_id, player2_id, seed, player1_deck, player2_deck, time_control FROM games WHERE id = $1"
+
+	setup := &GameSetup{GameID: gameID}
+	var seed sql.NullInt64
+	var player1DeckJSON, player2DeckJSON, timeControlJSON []byte
+	err := r.db.QueryRowContext(ctx, query, gameID).Scan(
+		&setup.Player1ID, &setup.Player2ID, &seed, &player1DeckJSON, &player2DeckJSON, &timeControlJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game setup: %w", err)
+	}
+
+	// 舊的遊戲沒有記錄初始設定，無法重建
+	if !seed.Valid || len(player1DeckJSON) == 0 || len(player2DeckJSON) == 0 {
+		return nil, fmt.Errorf("game has no recorded setup")
+	}
+	setup.Seed = seed.Int64
+
+	if err := json.Unmarshal(player1DeckJSON, &setup.Player1Deck); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal player1 deck: %w", err)
+	}
+	if err := json.Unmarshal(player2DeckJSON, &setup.Player2Deck); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal player2 deck: %w", err)
+	}
+	if len(timeControlJSON) > 0 {
+		setup.TimeControl = &models.TimeControl{}
+		if err := json.Unmarshal(timeControlJSON, setup.TimeControl); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal time control: %w", err)
+		}
+	}
+
+	return setup, nil
+}
+
+// UpdateGame 更新遊戲記錄的基本欄位
+// 遊戲狀態不在此寫入，只能經由 SaveGameState 以版本比對寫入，避免以讀取時的舊狀態覆蓋較新的狀態
 func (r *gameRepository) UpdateGame(ctx context.Context, game *models.Game) error {
 	query := `
 		UPDATE games SET
 			status = $2, current_turn = $3, phase = $4, active_player = $5,
-			game_state = $6, winner = $7, completed_at = $8, updated_at = $9
+			winner = $6, completed_at = $7, updated_at = $8
 		WHERE id = $1`
 
 	_, err := r.db.ExecContext(ctx, query,
 		game.ID, game.Status, game.CurrentTurn, game.Phase.String(),
-		game.ActivePlayer, game.GameState, game.Winner,
+		game.ActivePlayer, game.Winner,
 		game.CompletedAt, game.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to update game: %w", err)
 	}
 
-	// 更新 Redis 中的遊戲狀態
-	gameStateKey := fmt.Sprintf("game:%s:state", game.ID.String())
-	if err := r.redis.Set(ctx, gameStateKey, string(game.GameState), 24*time.Hour).Err(); err != nil {
-		return fmt.Errorf("failed to update cached game state: %w", err)
-	}
-
 
 	// 更新 Redis 中的遊戲基本信息 (使用 Hash 格式)
 	gameInfoKey := fmt.Sprintf("game:%s:info", game.ID.String())
@@ -199,46 +316,88 @@ func (r *gameRepository) UpdateGame(ctx context.Context, game *models.Game) erro
 	return nil
 }
 
-func (r *gameRepository) SaveGameState(ctx context.Context, gameID uuid.UUID, gameState *models.GameState) error {
-	gameStateKey := fmt.Sprintf("game:%s:state", gameID.String())
-
+func (r *gameRepository) SaveGameState(ctx context.Context, gameID uuid.UUID, gameState *models.GameState, action *models.GameAction) error {
 	gameStateJSON, err := json.Marshal(gameState)
 	if err != nil {
 		return fmt.Errorf("failed to marshal game state: %w", err)
 	}
+	actionJSON, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal action: %w", err)
+	}
+	expectedVersion := gameState.Version - 1
+	now := time.Now()
 
-	if err := r.redis.Set(ctx, gameStateKey, gameStateJSON, 24*time.Hour).Err(); err != nil {
-		return fmt.Errorf("failed to save game state to Redis: %w", err)
+	// 資料庫為狀態的權威來源，狀態與動作在同一交易中寫入，動作記錄的序號不會有缺漏
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Update both game state JSON and basic game fields (turn, phase, active_player)
-	query := "UPDATE games SET game_state = $1, current_turn = $2, phase = $3, active_player = $4, updated_at = $5 WHERE id = $6"
-	_, err = r.db.ExecContext(ctx, query, gameStateJSON, gameState.Turn, gameState.Phase.String(), gameState.ActivePlayer, time.Now(), gameID)
+	query := `
+		UPDATE games SET
+			game_state = $1, version = $2, current_turn = $3, phase = $4, active_player = $5, updated_at = $6
+		WHERE id = $7 AND version = $8 AND status IN ('WAITING', 'IN_PROGRESS')`
+	result, err := tx.ExecContext(ctx, query,
+		gameStateJSON, gameState.Version, gameState.Turn, gameState.Phase.String(), gameState.ActivePlayer, now,
+		gameID, expectedVersion)
 	if err != nil {
 		return fmt.Errorf("failed to save game state to database: %w", err)
 	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to save game state to database: %w", err)
+	}
+	if updated == 0 {
+		var status models.GameStatus
+		if err := tx.QueryRowContext(ctx, "SELECT status FROM games WHERE id = $1", gameID).Scan(&status); err != nil {
+			return fmt.Errorf("failed to check game status: %w", err)
+		}
+		if status != models.GameStatusWaiting && status != models.GameStatusInProgress {
+			return ErrGameNotInProgress
+		}
+		r.invalidateGameState(ctx, gameID)
+		return fmt.Errorf("%w: expected version %d", ErrVersionConflict, expectedVersion)
+	}
 
-	// 同步更新 Redis 中 game info 的 phase 和其他相關欄位
-	gameInfoKey := fmt.Sprintf("game:%s:info", gameID.String())
-	gameInfoFields := map[string]interface{}{
-		"current_turn":  gameState.Turn,
-		"phase":         gameState.Phase.String(),
-		"active_player": gameState.ActivePlayer.String(),
-		"updated_at":    time.Now().Format(time.RFC3339),
+	// (game_id, sequence_number) 唯一，同一序號的動作不會被記錄兩次
+	query = `
+		INSERT INTO game_actions (id, game_id, player_id, action_type, action_data,
+								 turn, phase, timestamp, is_valid, error_msg, sequence_number)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err = tx.ExecContext(ctx, query,
+		action.ID, action.GameID, action.PlayerID, action.ActionType,
+		action.ActionData, action.Turn, action.Phase.String(), action.Timestamp,
+		action.IsValid, action.ErrorMsg, action.SequenceNumber)
+	if err != nil {
+		return fmt.Errorf("failed to save action to database: %w", err)
 	}
 
-	if err := r.redis.HMSet(ctx, gameInfoKey, gameInfoFields).Err(); err != nil {
-		return fmt.Errorf("failed to update game info phase in Redis: %w", err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit game state: %w", err)
 	}
-	r.redis.Expire(ctx, gameInfoKey, 24*time.Hour)
+
+	// 已寫入資料庫後才更新 Redis；快取寫入失敗或版本不符時移除快取，讓之後的讀取回到資料庫
+	written, err := saveGameStateScript.Run(ctx, r.redis,
+		[]string{gameStateKey(gameID), gameVersionKey(gameID), fmt.Sprintf("game:%s:info", gameID.String())},
+		strconv.FormatInt(expectedVersion, 10), strconv.FormatInt(gameState.Version, 10), gameStateJSON,
+		int(gameStateTTL.Seconds()), gameState.Turn, gameState.Phase.String(), gameState.ActivePlayer.String(),
+		now.Format(time.RFC3339)).Int()
+	if err != nil || written == 0 {
+		r.invalidateGameState(ctx, gameID)
+	}
+
+	// 依時間順序附加到列表尾端
+	actionsKey := fmt.Sprintf("game:%s:actions", gameID.String())
+	r.redis.RPush(ctx, actionsKey, actionJSON)
+	r.redis.Expire(ctx, actionsKey, 24*time.Hour)
 
 	return nil
 }
 
 func (r *gameRepository) LoadGameState(ctx context.Context, gameID uuid.UUID) (*models.GameState, error) {
-	gameStateKey := fmt.Sprintf("game:%s:state", gameID.String())
-
-	gameStateJSON, err := r.redis.Get(ctx, gameStateKey).Result()
+	gameStateJSON, err := r.redis.Get(ctx, gameStateKey(gameID)).Result()
 	if err == nil {
 		var gameState models.GameState
 		if err := json.Unmarshal([]byte(gameStateJSON), &gameState); err == nil {
@@ -258,41 +417,23 @@ func (r *gameRepository) LoadGameState(ctx context.Context, gameID uuid.UUID) (*
 		return nil, fmt.Errorf("failed to unmarshal game state: %w", err)
 	}
 
-	r.redis.SetJSON(ctx, gameStateKey, &gameState, 24*time.Hour)
+	cacheGameStateScript.Run(ctx, r.redis, []string{gameStateKey(gameID), gameVersionKey(gameID)},
+		strconv.FormatInt(gameState.Version, 10), gameStateBytes, int(gameStateTTL.Seconds()))
 
 	return &gameState, nil
 }
 
-func (r *gameRepository) AddAction(ctx context.Context, gameID uuid.UUID, action *models.GameAction) error {
-	actionsKey := fmt.Sprintf("game:%s:actions", gameID.String())
-
-	actionJSON, err := json.Marshal(action)
-	if err != nil {
-		return fmt.Errorf("failed to marshal action: %w", err)
-	}
-
-	err = r.redis.LPush(ctx, actionsKey, actionJSON)
-	if err != nil {
-		return fmt.Errorf("failed to add action to Redis: %w", err)
-	}
-
-	r.redis.Expire(ctx, actionsKey, 24*time.Hour)
-
-	query := `
-		INSERT INTO game_actions (id, game_id, player_id, action_type, action_data,
-								 turn, phase, timestamp, is_valid, error_msg)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
-
-	_, err = r.db.ExecContext(ctx, query,
-		action.ID, action.GameID, action.PlayerID, action.ActionType,
-		action.ActionData, action.Turn, action.Phase.String(), action.Timestamp,
-		action.IsValid, action.ErrorMsg)
+// invalidateGameState 移除 Redis 中快取的遊戲狀態與版本
+func (r *gameRepository) invalidateGameState(ctx context.Context, gameID uuid.UUID) {
+	r.redis.Del(ctx, gameStateKey(gameID), gameVersionKey(gameID))
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to save action to database: %w", err)
-	}
+func gameStateKey(gameID uuid.UUID) string {
+	return fmt.Sprintf("game:%s:state", gameID.String())
+}
 
-	return nil
+func gameVersionKey(gameID uuid.UUID) string {
+	return fmt.Sprintf("game:%s:version", gameID.String())
 }
 
 func (r *gameRepository) GetActions(ctx context.Context, gameID uuid.UUID, fromIndex int) ([]*models.GameAction, error) {
@@ -312,10 +453,10 @@ func (r *gameRepository) GetActions(ctx context.Context, gameID uuid.UUID, fromI
 
 	query := `
 		SELECT id, game_id, player_id, action_type, action_data, turn, phase,
-			   timestamp, is_valid, error_msg
+			   timestamp, is_valid, error_msg, sequence_number
 		FROM game_actions 
 		WHERE game_id = $1
-		ORDER BY timestamp ASC
+		ORDER BY sequence_number ASC
 		OFFSET $2`
 
 	rows, err := r.db.QueryContext(ctx, query, gameID, fromIndex)
@@ -331,7 +472,7 @@ func (r *gameRepository) GetActions(ctx context.Context, gameID uuid.UUID, fromI
 		err := rows.Scan(
 			&action.ID, &action.GameID, &action.PlayerID, &action.ActionType,
 			&action.ActionData, &action.Turn, &phaseStr, &action.Timestamp,
-			&action.IsValid, &action.ErrorMsg)
+			&action.IsValid, &action.ErrorMsg, &action.SequenceNumber)
 		if err != nil {
 			continue
 		}
@@ -342,6 +483,40 @@ func (r *gameRepository) GetActions(ctx context.Context, gameID uuid.UUID, fromI
 	return actions, nil
 }
 
+func (r *gameRepository) GetActionLog(ctx context.Context, gameID uuid.UUID, uptoSeq int64) ([]models.GameAction, error) {
+	query := `
+		SELECT id, game_id, player_id, action_type, action_data, turn, phase,
+			   timestamp, is_valid, COALESCE(error_msg, ''), sequence_number
+		FROM game_actions
+		WHERE game_id = $1 AND ($2 <= 0 OR sequence_number <= $2)
+		ORDER BY sequence_number ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, gameID, uptoSeq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action log: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []models.GameAction{}
+	for rows.Next() {
+		var action models.GameAction
+		var phaseStr string
+		if err := rows.Scan(
+			&action.ID, &action.GameID, &action.PlayerID, &action.ActionType,
+			&action.ActionData, &action.Turn, &phaseStr, &action.Timestamp,
+			&action.IsValid, &action.ErrorMsg, &action.SequenceNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan action: %w", err)
+		}
+		action.Phase = models.ParsePhase(phaseStr)
+		actions = append(actions, action)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read action log: %w", err)
+	}
+
+	return actions, nil
+}
+
 func (r *gameRepository) UpdateGameStatus(ctx context.Context, gameID uuid.UUID, status models.GameStatus) error {
 	query := "UPDATE games SET status = $1, updated_at = $2 WHERE id = $3"
 	_, err := r.db.ExecContext(ctx, query, status, time.Now(), gameID)
@@ -402,18 +577,71 @@ func (r *gameRepository) GetActiveGames(ctx context.Context, playerID uuid.UUID)
 	return games, nil
 }
 
-func (r *gameRepository) SetGameWinner(ctx context.Context, gameID uuid.UUID, winner uuid.UUID, reason string) error {
+func (r *gameRepository) SetGameWinner(ctx context.Context, gameID uuid.UUID, winner uuid.UUID, endReason string) error {
 	now := time.Now()
 	query := `
 		UPDATE games SET 
-			winner = $1, status = 'COMPLETED', completed_at = $2, updated_at = $3
-		WHERE id = $4`
+			winner = $1, end_reason = $2, result_pending = true, status = 'COMPLETED', completed_at = $3, updated_at = $4
+		WHERE id = $5 AND status = 'IN_PROGRESS'`
 
-	_, err := r.db.ExecContext(ctx, query, winner, now, now, gameID)
+	result, err := r.db.ExecContext(ctx, query, winner, endReason, now, now, gameID)
+	if err != nil {
+		return fmt.Errorf("failed to set game winner: %w", err)
+	}
+	updated, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to set game winner: %w", err)
 	}
+	if updated == 0 {
+		return ErrGameNotInProgress
+	}
+
+	return nil
+}
+
+// GetPendingResults 讀取在 completedBefore 之前結束、結果尚未回報的遊戲，不含遊戲狀態，最早結束的遊戲在前
+func (r *gameRepository) GetPendingResults(ctx context.Context, completedBefore time.Time, limit int) ([]*models.Game, error) {
+	query := `
+		SELECT id, player1_id, player2_id, status, current_turn, winner, end_reason,
+			   COALESCE(game_mode, 'RANKED'), started_at, completed_at, created_at, updated_at
+		FROM games
+		WHERE result_pending AND completed_at < $1
+		ORDER BY completed_at
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, completedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending results: %w", err)
+	}
+	defer rows.Close()
+
+	var games []*models.Game
+	for rows.Next() {
+		game := &models.Game{}
+		var endReason sql.NullString
+
+		err := rows.Scan(
+			&game.ID, &game.Player1ID, &game.Player2ID, &game.Status, &game.CurrentTurn,
+			&game.Winner, &endReason, &game.GameMode,
+			&game.StartedAt, &game.CompletedAt, &game.CreatedAt, &game.UpdatedAt)
+		if err != nil {
+			continue
+		}
+
+		game.EndReason = endReason.String
+		games = append(games, game)
+	}
 
+	return games, nil
+}
+
+// MarkResultReported 將遊戲結果標記為已回報
+func (r *gameRepository) MarkResultReported(ctx context.Context, gameID uuid.UUID) error {
+	query := `UPDATE games SET result_pending = false WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, gameID); err != nil {
+		return fmt.Errorf("failed to mark result reported: %w", err)
+	}
 	return nil
 }
 
@@ -460,6 +688,44 @@ func (r *gameRepository) GetGamesByStatus(ctx context.Context, status models.Gam
 	return games, nil
 }
 
+// GetSpectatableGames 讀取進行中且允許觀戰的遊戲，不含遊戲狀態，最近開始的遊戲在前
+func (r *gameRepository) GetSpectatableGames(ctx context.Context, limit int) ([]*models.Game, error) {
+	query := `
+		SELECT id, player1_id, player2_id, status, current_turn, phase,
+			   active_player, COALESCE(game_mode, 'RANKED'), allow_spectators, spectator_delay,
+			   started_at, created_at, updated_at
+		FROM games 
+		WHERE status = 'IN_PROGRESS' AND allow_spectators
+		ORDER BY started_at DESC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spectatable games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []*models.Game
+	for rows.Next() {
+		game := &models.Game{}
+		var phaseStr string
+
+		err := rows.Scan(
+			&game.ID, &game.Player1ID, &game.Player2ID, &game.Status,
+			&game.CurrentTurn, &phaseStr, &game.ActivePlayer, &game.GameMode,
+			&game.AllowSpectators, &game.SpectatorDelay,
+			&game.StartedAt, &game.CreatedAt, &game.UpdatedAt)
+		if err != nil {
+			continue
+		}
+
+		game.Phase = models.ParsePhase(phaseStr)
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
 // GetGameStatusFromRedis 從 Redis game info 獲取遊戲狀態
 func (r *gameRepository) GetGameStatusFromRedis(ctx context.Context, gameID uuid.UUID) (models.GameStatus, error) {
 	gameInfoKey := fmt.Sprintf("game:%s:info", gameID.String())
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// botTurnTimeout bounds one run of a game's bots
+const botTurnTimeout = 2 * time.Minute
+
+// botRunners tracks the games whose bots are playing, so every game has at most one goroutine running its bots
+type botRunners struct {
+	mu sync.Mutex
+	// Whether another run was asked for while the game's bots were playing, by game
+	again map[uuid.UUID]bool
+}
+
+func newBotRunners() *botRunners {
+	return &botRunners{again: make(map[uuid.UUID]bool)}
+}
+
+// start reports whether the caller should run the game's bots; otherwise the running goroutine runs them once more
+func (r *botRunners) start(gameID uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, running := r.again[gameID]; running {
+		r.again[gameID] = true
+		return false
+	}
+	r.again[gameID] = false
+	return true
+}
+
+// done reports whether the game's bots have to run again, and otherwise marks the game as no longer running
+func (r *botRunners) done(gameID uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.again[gameID] {
+		r.again[gameID] = false
+		return true
+	}
+	delete(r.again, gameID)
+	return false
+}
+
+// scheduleBots lets the game's bots act in the background, so the request that gave them the turn is answered first.
+// Their moves reach the players through the regular broadcasts.
+func (s *gameService) scheduleBots(gameID uuid.UUID) {
+	if !s.botRunners.start(gameID) {
+		return
+	}
+
+	go func() {
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), botTurnTimeout)
+			s.runBots(ctx, gameID)
+			cancel()
+
+			if !s.botRunners.done(gameID) {
+				return
+			}
+		}
+	}()
+}
@@ -3,13 +3,18 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"ua/services/game-battle-service/internal/bot"
 	"ua/services/game-battle-service/internal/engine"
 	"ua/services/game-battle-service/internal/repository"
+	"ua/shared/config"
 	"ua/shared/logger"
 	"ua/shared/models"
+	"ua/shared/websocket"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -23,9 +28,18 @@ type GameService interface {
 	GetGame(ctx context.Context, gameID uuid.UUID, playerID uuid.UUID) (*GameResponse, error)
 	GetGameInfo(ctx context.Context, gameID uuid.UUID) (map[string]interface{}, error)
 	GetTurnInfo(ctx context.Context, gameID uuid.UUID) (*TurnInfoResponse, error)
+	GetLegalActions(ctx context.Context, gameID uuid.UUID, playerID uuid.UUID) (*LegalActionsResponse, error)
+	RebuildGameState(ctx context.Context, gameID uuid.UUID, uptoSeq int64) (*models.GameState, error)
+	GetReplayStep(ctx context.Context, gameID uuid.UUID, step int) (*ReplayStepResponse, error)
+	StreamReplay(ctx context.Context, gameID uuid.UUID, from int, emit func(*ReplayStepResponse) error) error
 	GetActiveGames(ctx context.Context, playerID uuid.UUID) (*ActiveGamesResponse, error)
 	SurrenderGame(ctx context.Context, gameID uuid.UUID, playerID uuid.UUID) (*GameResponse, error)
+	GetSpectatorView(ctx context.Context, gameID uuid.UUID, userID uuid.UUID) (*SpectatorUpdate, error)
+	GetSpectatableGames(ctx context.Context) (*SpectatableGamesResponse, error)
 	ProcessGameEngine(ctx context.Context, gameID uuid.UUID) error
+	RunTimers(ctx context.Context)
+	UserConnected(userID uuid.UUID)
+	UserDisconnected(userID uuid.UUID)
 }
 
 type CreateGameRequest struct {
@@ -34,6 +48,10 @@ type CreateGameRequest struct {
 	GameMode    string        `json:"game_mode" binding:"required"`
 	Player1Deck []models.Card `json:"player1_deck" binding:"required"`
 	Player2Deck []models.Card `json:"player2_deck" binding:"required"`
+	// Spectators are allowed unless the players opt out, e.g. for a friend game
+	AllowSpectators *bool `json:"allow_spectators,omitempty"`
+	// Number of actions spectators lag behind the game, to keep players from watching their own stream
+	SpectatorDelay int `json:"spectator_delay,omitempty" binding:"min=0"`
 }
 
 type MulliganRequest struct {
@@ -45,8 +63,8 @@ type MulliganRequest struct {
 type PlayActionRequest struct {
 	GameID     uuid.UUID `json:"game_id" binding:"required"`
 	PlayerID   uuid.UUID `json:"player_id" binding:"required"`
-	ActionType string    `json:"action_type" binding:"required"`
-	ActionData []int     `json:"action_data,omitempty"`
+	ActionType string          `json:"action_type" binding:"required"`
+	ActionData json.RawMessage `json:"action_data,omitempty"`
 }
 
 type GameResponse struct {
@@ -80,6 +98,30 @@ type TurnInfoResponse struct {
 	GameStatus   models.GameStatus `json:"game_status"`
 }
 
+type LegalActionsResponse struct {
+	GameID       uuid.UUID     `json:"game_id"`
+	PlayerID     uuid.UUID     `json:"player_id"`
+	Turn         int           `json:"turn"`
+	Phase        models.Phase  `json:"phase"`
+	ActivePlayer uuid.UUID     `json:"active_player"`
+	Actions      []LegalAction `json:"actions"`
+}
+
+type ReplayStepResponse struct {
+	GameID          uuid.UUID          `json:"game_id"`
+	Step            int                `json:"step"`
+	TotalSteps      int                `json:"total_steps"`
+	Action          *models.GameAction `json:"action,omitempty"`
+	GameState       *models.GameState  `json:"game_state"`
+	Effects         []EffectResult     `json:"effects"`
+	EventsTriggered []GameEvent        `json:"events_triggered"`
+}
+
+type LegalAction struct {
+	ActionType string            `json:"action_type"`
+	ActionData models.ActionData `json:"action_data"`
+}
+
 type GameInfo struct {
 	ID           uuid.UUID         `json:"id"`
 	Player1ID    uuid.UUID         `json:"player1_id"`
@@ -89,6 +131,9 @@ type GameInfo struct {
 	Phase        models.Phase      `json:"phase"`
 	ActivePlayer uuid.UUID         `json:"active_player"`
 	Winner       *uuid.UUID        `json:"winner,omitempty"`
+	GameMode        string         `json:"game_mode"`
+	AllowSpectators bool           `json:"allow_spectators"`
+	SpectatorDelay  int            `json:"spectator_delay"`
 	StartedAt    *time.Time        `json:"started_at,omitempty"`
 	CompletedAt  *time.Time        `json:"completed_at,omitempty"`
 	CreatedAt    time.Time         `json:"created_at"`
@@ -112,20 +157,72 @@ type GameEvent struct {
 	Timestamp time.Time              `json:"timestamp"`
 }
 
+// maxBotActions caps how many actions bots may take in a row before a human has to act
+const maxBotActions = 500
+
+// ErrStateConflict is returned when another request or replica saved a newer game state first.
+// The latest saved state has been reloaded by then, so the request can simply be retried.
+var ErrStateConflict = errors.New("game state was changed by another request, please retry")
+
+// Notifier delivers WebSocket messages to players and spectators
+type Notifier interface {
+	SendToUser(userID uuid.UUID, message []byte)
+	BroadcastToSpectators(gameID uuid.UUID, message []byte)
+	SpectatorCount(gameID uuid.UUID) int
+	IsConnected(userID uuid.UUID) bool
+}
+
+// GameUpdate is pushed to each player after the game state changes.
+// The state is projected for the receiving player, so it never shows the opponent's hidden cards.
+type GameUpdate struct {
+	GameID          uuid.UUID         `json:"game_id"`
+	GameState       *models.GameState `json:"game_state"`
+	Effects         []EffectResult    `json:"effects"`
+	EventsTriggered []GameEvent       `json:"events_triggered"`
+	Spectators      int               `json:"spectators"`
+	// Lets clients count down the running clock from clock_started_at without trusting their own time
+	ServerTime time.Time `json:"server_time"`
+}
+
+const MessageTypeGameUpdate = "GAME_UPDATE"
+
 type gameService struct {
-	gameRepo   repository.GameRepository
-	gameEngine engine.GameEngine
+	gameRepo    repository.GameRepository
+	gameEngine  engine.GameEngine
+	bots        *bot.Roster
+	notifier    Notifier
+	results     ResultPublisher
+	spectators  *spectatorFeeds
+	disconnects *disconnectedSeats
+	views       *playerViews
+	botRunners  *botRunners
+	// How long a player may stay disconnected from a game in progress before losing it; 0 or less never ends the game
+	reconnectGrace time.Duration
 }
 
-func NewGameService(gameRepo repository.GameRepository, gameEngine engine.GameEngine) GameService {
+func NewGameService(gameRepo repository.GameRepository, gameEngine engine.GameEngine, bots *bot.Roster, notifier Notifier, results ResultPublisher) GameService {
 	return &gameService{
-		gameRepo:   gameRepo,
-		gameEngine: gameEngine,
+		gameRepo:       gameRepo,
+		gameEngine:     gameEngine,
+		bots:           bots,
+		notifier:       notifier,
+		results:        results,
+		spectators:     newSpectatorFeeds(),
+		disconnects:    newDisconnectedSeats(),
+		views:          newPlayerViews(),
+		botRunners:     newBotRunners(),
+		reconnectGrace: time.Duration(config.GetEnvInt("RECONNECT_GRACE_SECONDS", 60)) * time.Second,
 	}
 }
 
 func (s *gameService) CreateGame(ctx context.Context, req *CreateGameRequest) (*GameResponse, error) {
+	gameMode := strings.ToUpper(req.GameMode)
+	if gameMode != models.MatchModeRanked && gameMode != models.MatchModeCasual && gameMode != models.MatchModeFriend {
+		return nil, fmt.Errorf("invalid game mode: %s", req.GameMode)
+	}
+
 	gameID := uuid.New()
+	timeControl := timeControlFor(gameMode)
 
 	// Initialize game through engine
 	initReq := &engine.InitGameRequest{
@@ -138,6 +235,7 @@ func (s *gameService) CreateGame(ctx context.Context, req *CreateGameRequest) (*
 			UserID: req.Player2ID,
 			Deck:   req.Player2Deck,
 		},
+		TimeControl: timeControl,
 	}
 
 	gameState, err := s.gameEngine.InitializeGame(ctx, initReq)
@@ -161,11 +259,25 @@ func (s *gameService) CreateGame(ctx context.Context, req *CreateGameRequest) (*
 		Phase:        models.StartPhase,
 		ActivePlayer: req.Player1ID,
 		GameState:    gameStateJSON,
+		GameMode:        gameMode,
+		AllowSpectators: req.AllowSpectators == nil || *req.AllowSpectators,
+		SpectatorDelay:  req.SpectatorDelay,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
-	if err := s.gameRepo.CreateGame(ctx, game); err != nil {
+	// Keep the seed and the unshuffled decks so the game can be rebuilt from its action log
+	setup := &repository.GameSetup{
+		GameID:      gameID,
+		Seed:        gameState.Seed,
+		Player1ID:   req.Player1ID,
+		Player2ID:   req.Player2ID,
+		Player1Deck: req.Player1Deck,
+		Player2Deck: req.Player2Deck,
+		TimeControl: timeControl,
+	}
+
+	if err := s.gameRepo.CreateGame(ctx, game, setup); err != nil {
 		return nil, fmt.Errorf("failed to create game record: %w", err)
 	}
 
@@ -176,9 +288,20 @@ func (s *gameService) CreateGame(ctx context.Context, req *CreateGameRequest) (*
 		zap.String("player1", req.Player1ID.String()),
 		zap.String("player2", req.Player2ID.String()))
 
+	// Bots take their seat right away so the game starts once the human player joins
+	if seated, err := s.seatBots(ctx, gameID, req.Player1ID, req.Player2ID); err != nil {
+		return nil, err
+	} else if seated {
+		if game, err = s.gameRepo.GetGame(ctx, gameID); err != nil {
+			return nil, fmt.Errorf("failed to get game: %w", err)
+		}
+		gameInfo = s.modelToGameInfo(game)
+	}
+
+	// The game is created on behalf of both players, so only the public view is returned
 	return &GameResponse{
 		Game:      gameInfo,
-		GameState: gameState,
+		GameState: engine.ViewGameState(gameState, uuid.Nil),
 		Message:   "Game created successfully",
 	}, nil
 }
@@ -258,23 +381,32 @@ func (s *gameService) JoinGame(ctx context.Context, gameID uuid.UUID, playerID u
 
 	return &GameResponse{
 		Game:      gameInfo,
-		GameState: gameState,
+		GameState: engine.ViewGameState(gameState, playerID),
 		Message:   message,
 	}, nil
 }
 
 
 func (s *gameService) PlayAction(ctx context.Context, req *PlayActionRequest) (*ActionResponse, error) {
-	// Convert ActionData from []int to json.RawMessage
-	var actionDataJSON json.RawMessage
-	if len(req.ActionData) > 0 {
-		actionDataBytes, err := json.Marshal(req.ActionData)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal action data: %w", err)
-		}
-		actionDataJSON = json.RawMessage(actionDataBytes)
-	} else {
-		actionDataJSON = json.RawMessage("[]")
+	// Only the server reports a lost connection
+	if req.ActionType == models.ActionTypeConnectionLost {
+		return nil, fmt.Errorf("invalid action type")
+	}
+
+	response, err := s.playAction(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.scheduleBots(req.GameID)
+	return response, nil
+}
+
+func (s *gameService) playAction(ctx context.Context, req *PlayActionRequest) (*ActionResponse, error) {
+	// Default to an empty object when the client sends no action data
+	actionDataJSON := req.ActionData
+	if len(actionDataJSON) == 0 {
+		actionDataJSON = json.RawMessage("{}")
 	}
 
 	// Create game action
@@ -301,6 +433,10 @@ func (s *gameService) PlayAction(ctx context.Context, req *PlayActionRequest) (*
 			if dbErr != nil {
 				return nil, fmt.Errorf("game not found")
 			}
+			// Games are unloaded when they end, so a finished game must not be loaded back for another action
+			if game.Status != models.GameStatusInProgress {
+				return nil, fmt.Errorf("game is not in progress")
+			}
 
 			// Deserialize game state and load into engine
 			if len(game.GameState) > 0 {
@@ -330,53 +466,40 @@ func (s *gameService) PlayAction(ctx context.Context, req *PlayActionRequest) (*
 
 	// Check if the action was not successful and return as error for proper HTTP status handling
 	if !result.Success {
-		return nil, fmt.Errorf(result.Error)
+		return nil, errors.New(result.Error)
 	}
 
-	// Save action to database
-	if err := s.gameRepo.AddAction(ctx, req.GameID, action); err != nil {
-		logger.Error("Failed to save action", zap.Error(err))
-		// Don't fail the request, just log the error
+	// The new state and the action that produced it are stored together, so the action log never has gaps
+	if err := s.gameRepo.SaveGameState(ctx, req.GameID, result.GameState, action); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.resyncGame(ctx, req.GameID, err)
+			return nil, ErrStateConflict
+		}
+		if errors.Is(err, repository.ErrGameNotInProgress) {
+			// The game ended while the action was processed, e.g. by a surrender
+			s.gameEngine.UnloadGame(ctx, req.GameID)
+			return nil, fmt.Errorf("game is not in progress")
+		}
+		// The engine has moved past the stored state; drop its copy so the next request reloads what was saved
+		s.gameEngine.UnloadGame(ctx, req.GameID)
+		return nil, fmt.Errorf("failed to save game state: %w", err)
 	}
 
 	// Convert engine result to service response
 	response := &ActionResponse{
 		Success:         result.Success,
 		Error:           result.Error,
-		GameState:       result.GameState,
-		Effects:         []EffectResult{},
-		EventsTriggered: []GameEvent{},
+		GameState:       engine.ViewGameState(result.GameState, req.PlayerID),
+		Effects:         toEffectResults(result.Effects),
+		EventsTriggered: toGameEvents(result.EventsTriggered),
 		NextPhase:       result.NextPhase,
 	}
 
-	// Convert effects
-	for _, effect := range result.Effects {
-		response.Effects = append(response.Effects, EffectResult{
-			Type:        effect.Type,
-			Source:      effect.Source,
-			Target:      effect.Target,
-			Value:       effect.Value,
-			Description: effect.Description,
-			Applied:     effect.Applied,
-		})
-	}
+	s.broadcastState(ctx, req.GameID, result.GameState, response.Effects, response.EventsTriggered)
 
-	// Convert events
-	for _, event := range result.EventsTriggered {
-		response.EventsTriggered = append(response.EventsTriggered, GameEvent{
-			Type:      event.Type,
-			Source:    event.Source,
-			Target:    event.Target,
-			Data:      event.Data,
-			Timestamp: event.Timestamp,
-		})
-	}
-
-	// Update game state in repository if action was successful
-	if result.Success && result.GameState != nil {
-		if err := s.gameRepo.SaveGameState(ctx, req.GameID, result.GameState); err != nil {
-			logger.Error("Failed to save game state", zap.Error(err))
-		}
+	// Record the result once an action decides the game
+	if winCondition, err := s.gameEngine.CheckWinCondition(ctx, result.GameState); err == nil && winCondition.HasWinner && winCondition.Winner != nil {
+		s.completeGame(ctx, req.GameID, winCondition)
 	}
 
 	logger.Debug("Action processed",
@@ -400,6 +523,7 @@ func (s *gameService) GetGame(ctx context.Context, gameID uuid.UUID, playerID uu
 	}
 
 	gameInfo := s.modelToGameInfo(game)
+	live := game.Status == models.GameStatusWaiting || game.Status == models.GameStatusInProgress
 
 	// Deserialize game state
 	var gameState *models.GameState
@@ -407,17 +531,23 @@ func (s *gameService) GetGame(ctx context.Context, gameID uuid.UUID, playerID uu
 		gameState = &models.GameState{}
 		if err := json.Unmarshal(game.GameState, gameState); err != nil {
 			logger.Error("Failed to deserialize game state", zap.Error(err))
-		} else {
-			// Load game state into engine memory for future actions
+		} else if live {
+			// Load game state into engine memory for future actions; finished games stay unloaded
 			if loadErr := s.loadGameStateIntoEngine(ctx, gameID, gameState); loadErr != nil {
 				logger.Error("Failed to load game state into engine", zap.Error(loadErr))
 			}
 		}
 	}
 
+	view := engine.ViewGameState(gameState, playerID)
+	if live {
+		// Clients resync through here, so the player's next patch is made against this view
+		s.rememberView(gameID, playerID, view)
+	}
+
 	return &GameResponse{
 		Game:      gameInfo,
-		GameState: gameState,
+		GameState: view,
 	}, nil
 }
 
@@ -451,6 +581,49 @@ func (s *gameService) GetTurnInfo(ctx context.Context, gameID uuid.UUID) (*TurnI
 	return response, nil
 }
 
+func (s *gameService) GetLegalActions(ctx context.Context, gameID uuid.UUID, playerID uuid.UUID) (*LegalActionsResponse, error) {
+	gameState, err := s.gameEngine.GetGameState(ctx, gameID)
+	if err != nil {
+		// Fall back to the stored state when the game is not in engine memory
+		game, dbErr := s.gameRepo.GetGame(ctx, gameID)
+		if dbErr != nil || len(game.GameState) == 0 {
+			return nil, fmt.Errorf("game not found")
+		}
+
+		gameState = &models.GameState{}
+		if err := json.Unmarshal(game.GameState, gameState); err != nil {
+			return nil, fmt.Errorf("failed to load game state: %w", err)
+		}
+		// Finished games are answered from the stored state without loading them again
+		if game.Status == models.GameStatusWaiting || game.Status == models.GameStatusInProgress {
+			if err := s.loadGameStateIntoEngine(ctx, gameID, gameState); err != nil {
+				return nil, fmt.Errorf("failed to load game into engine: %w", err)
+			}
+		}
+	}
+
+	if _, exists := gameState.Players[playerID]; !exists {
+		return nil, fmt.Errorf("player not part of this game")
+	}
+
+	response := &LegalActionsResponse{
+		GameID:       gameID,
+		PlayerID:     playerID,
+		Turn:         gameState.Turn,
+		Phase:        gameState.Phase,
+		ActivePlayer: gameState.ActivePlayer,
+		Actions:      []LegalAction{},
+	}
+	for _, action := range s.gameEngine.LegalActions(gameState, playerID) {
+		response.Actions = append(response.Actions, LegalAction{
+			ActionType: action.ActionType,
+			ActionData: action.ActionData,
+		})
+	}
+
+	return response, nil
+}
+
 func (s *gameService) GetActiveGames(ctx context.Context, playerID uuid.UUID) (*ActiveGamesResponse, error) {
 	games, err := s.gameRepo.GetActiveGames(ctx, playerID)
 	if err != nil {
@@ -490,10 +663,17 @@ func (s *gameService) SurrenderGame(ctx context.Context, gameID uuid.UUID, playe
 	}
 
 	// Set game winner
-	if err := s.gameRepo.SetGameWinner(ctx, gameID, winner, "surrender"); err != nil {
+	if err := s.gameRepo.SetGameWinner(ctx, gameID, winner, models.EndReasonSurrender); err != nil {
+		if errors.Is(err, repository.ErrGameNotInProgress) {
+			// The game was decided just before the surrender
+			return nil, fmt.Errorf("game is not in progress")
+		}
 		return nil, fmt.Errorf("failed to set game winner: %w", err)
 	}
 
+	s.releaseGame(ctx, gameID)
+	s.reportResult(ctx, gameID, winner, models.EndReasonSurrender)
+
 	// Get updated game
 	game, err = s.gameRepo.GetGame(ctx, gameID)
 	if err != nil {
@@ -536,18 +716,35 @@ func (s *gameService) PerformMulligan(ctx context.Context, req *MulliganRequest)
 	}
 
 	// Process mulligan through game engine
-	if err := s.gameEngine.PerformMulligan(ctx, engineReq); err != nil {
+	updatedGameState, err := s.gameEngine.PerformMulligan(ctx, engineReq)
+	if err != nil {
 		return nil, fmt.Errorf("failed to perform mulligan: %w", err)
 	}
 
-	// Get updated game state
-	updatedGameState, err := s.gameEngine.GetGameState(ctx, req.GameID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get updated game state: %w", err)
+	// Record the mulligan in the action log so replays shuffle the same way; it is stored with the state it produced
+	mulliganData, _ := json.Marshal(engine.MulliganActionData{Mulligan: req.Mulligan})
+	action := &models.GameAction{
+		ID:             uuid.New(),
+		GameID:         req.GameID,
+		PlayerID:       req.PlayerID,
+		ActionType:     models.ActionTypeMulligan,
+		ActionData:     mulliganData,
+		Turn:           1,
+		Phase:          models.StartPhase,
+		Timestamp:      engineReq.PerformedAt,
+		IsValid:        true,
+		SequenceNumber: updatedGameState.Version,
 	}
 
-	// Save updated game state
-	if err := s.gameRepo.SaveGameState(ctx, req.GameID, updatedGameState); err != nil {
+	if err := s.gameRepo.SaveGameState(ctx, req.GameID, updatedGameState, action); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.resyncGame(ctx, req.GameID, err)
+			return nil, ErrStateConflict
+		}
+		s.gameEngine.UnloadGame(ctx, req.GameID)
+		if errors.Is(err, repository.ErrGameNotInProgress) {
+			return nil, fmt.Errorf("game is not in progress")
+		}
 		return nil, fmt.Errorf("failed to save game state: %w", err)
 	}
 
@@ -558,16 +755,21 @@ func (s *gameService) PerformMulligan(ctx context.Context, req *MulliganRequest)
 		zap.String("player_id", req.PlayerID.String()),
 		zap.Bool("mulligan", req.Mulligan))
 
+	s.broadcastState(ctx, req.GameID, updatedGameState, []EffectResult{}, []GameEvent{})
+
+	// A bot may be the first player once both hands are kept
+	s.scheduleBots(req.GameID)
+
 	return &GameResponse{
 		Game:      gameInfo,
-		GameState: updatedGameState,
+		GameState: engine.ViewGameState(updatedGameState, req.PlayerID),
 		Message:   "Mulligan completed",
 	}, nil
 }
 
 func (s *gameService) ProcessGameEngine(ctx context.Context, gameID uuid.UUID) error {
-	// This method can be called periodically to process game logic
-	// such as turn timers, automatic actions, etc.
+	// This method can be called to settle games whose stored state already has a winner.
+	// Turn timers are run by RunTimers.
 
 	game, err := s.gameRepo.GetGame(ctx, gameID)
 	if err != nil {
@@ -596,9 +798,11 @@ func (s *gameService) ProcessGameEngine(ctx context.Context, gameID uuid.UUID) e
 	}
 
 	if winCondition.HasWinner && winCondition.Winner != nil {
-		if err := s.gameRepo.SetGameWinner(ctx, gameID, *winCondition.Winner, winCondition.Reason); err != nil {
+		if err := s.gameRepo.SetGameWinner(ctx, gameID, *winCondition.Winner, winCondition.EndReason); err != nil {
 			return fmt.Errorf("failed to set game winner: %w", err)
 		}
+		s.releaseGame(ctx, gameID)
+		s.reportResult(ctx, gameID, *winCondition.Winner, winCondition.EndReason)
 
 		logger.Info("Game ended",
 			zap.String("game_id", gameID.String()),
@@ -609,6 +813,310 @@ func (s *gameService) ProcessGameEngine(ctx context.Context, gameID uuid.UUID) e
 	return nil
 }
 
+// seatBots joins the game and performs the mulligan for every bot among the players
+func (s *gameService) seatBots(ctx context.Context, gameID uuid.UUID, playerIDs ...uuid.UUID) (bool, error) {
+	seated := false
+	for _, playerID := range playerIDs {
+		b, isBot := s.bots.Get(playerID)
+		if !isBot {
+			continue
+		}
+
+		if _, err := s.JoinGame(ctx, gameID, playerID); err != nil {
+			return seated, fmt.Errorf("bot failed to join game: %w", err)
+		}
+
+		gameState, err := s.gameEngine.GetGameState(ctx, gameID)
+		if err != nil {
+			return seated, fmt.Errorf("failed to get game state: %w", err)
+		}
+
+		mulligan := b.Mulligan(gameState.Players[playerID].Hand)
+		if _, err := s.PerformMulligan(ctx, &MulliganRequest{GameID: gameID, PlayerID: playerID, Mulligan: mulligan}); err != nil {
+			return seated, fmt.Errorf("bot failed to mulligan: %w", err)
+		}
+
+		seated = true
+		logger.Info("Bot seated",
+			zap.String("game_id", gameID.String()),
+			zap.String("bot_id", playerID.String()),
+			zap.String("strategy", b.Strategy.Name()))
+	}
+	return seated, nil
+}
+
+// runBots lets bots act through the regular action path until a human player has to act or the game ends.
+// It is run through scheduleBots.
+func (s *gameService) runBots(ctx context.Context, gameID uuid.UUID) {
+	for i := 0; i < maxBotActions; i++ {
+		gameState, err := s.gameEngine.GetGameState(ctx, gameID)
+		if err != nil {
+			return
+		}
+
+		if winCondition, err := s.gameEngine.CheckWinCondition(ctx, gameState); err != nil || winCondition.HasWinner {
+			return
+		}
+
+		acted := false
+		for playerID := range gameState.Players {
+			b, isBot := s.bots.Get(playerID)
+			if !isBot {
+				continue
+			}
+
+			action, ok := b.NextAction(gameState)
+			if !ok {
+				continue
+			}
+
+			actionData, err := json.Marshal(action.ActionData)
+			if err != nil {
+				logger.Error("Failed to serialize bot action", zap.Error(err))
+				return
+			}
+
+			if _, err := s.playAction(ctx, &PlayActionRequest{
+				GameID:     gameID,
+				PlayerID:   playerID,
+				ActionType: action.ActionType,
+				ActionData: actionData,
+			}); err != nil {
+				logger.Error("Bot action failed",
+					zap.String("game_id", gameID.String()),
+					zap.String("bot_id", playerID.String()),
+					zap.String("action_type", action.ActionType),
+					zap.Error(err))
+				return
+			}
+
+			acted = true
+			break
+		}
+
+		if !acted {
+			return
+		}
+	}
+
+	logger.Error("Bots reached the action limit without handing over to a player",
+		zap.String("game_id", gameID.String()),
+		zap.Int("limit", maxBotActions))
+}
+
+// RebuildGameState recreates a game as it was after the action with the given sequence number
+// (or after its last recorded action when uptoSeq is 0) by replaying the action log through a separate engine.
+func (s *gameService) RebuildGameState(ctx context.Context, gameID uuid.UUID, uptoSeq int64) (*models.GameState, error) {
+	setup, err := s.gameRepo.GetGameSetup(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.gameRepo.GetActionLog(ctx, gameID, uptoSeq)
+	if err != nil {
+		return nil, err
+	}
+	if uptoSeq > 0 && int64(len(actions)) < uptoSeq {
+		return nil, fmt.Errorf("action log ends before sequence %d", uptoSeq)
+	}
+
+	seed := setup.Seed
+	gameState, err := engine.ReplayGame(ctx, &engine.InitGameRequest{
+		GameID:      gameID,
+		Player1:     &engine.PlayerSetup{UserID: setup.Player1ID, Deck: setup.Player1Deck},
+		Player2:     &engine.PlayerSetup{UserID: setup.Player2ID, Deck: setup.Player2Deck},
+		Seed:        &seed,
+		TimeControl: setup.TimeControl,
+	}, actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild game state: %w", err)
+	}
+
+	return gameState, nil
+}
+
+// errReplayStopped ends a replay walk early without reporting an error
+var errReplayStopped = errors.New("replay stopped")
+
+// GetReplayStep returns a finished game as it was after the given number of recorded actions.
+// Step 0 is the dealt opening hands; the last step is the end of the game.
+func (s *gameService) GetReplayStep(ctx context.Context, gameID uuid.UUID, step int) (*ReplayStepResponse, error) {
+	var response *ReplayStepResponse
+	err := s.StreamReplay(ctx, gameID, step, func(replayStep *ReplayStepResponse) error {
+		response = replayStep
+		return errReplayStopped
+	})
+	if err != nil && !errors.Is(err, errReplayStopped) {
+		return nil, err
+	}
+	return response, nil
+}
+
+// StreamReplay replays a finished game and calls emit with every step from the given one to the end.
+// Replays are only available once the game is over, so every zone is shown as it was.
+func (s *gameService) StreamReplay(ctx context.Context, gameID uuid.UUID, from int, emit func(*ReplayStepResponse) error) error {
+	game, err := s.gameRepo.GetGame(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("game not found")
+	}
+	if game.Status != models.GameStatusCompleted && game.Status != models.GameStatusAbandoned {
+		return fmt.Errorf("game is not finished")
+	}
+
+	setup, err := s.gameRepo.GetGameSetup(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	actions, err := s.gameRepo.GetActionLog(ctx, gameID, 0)
+	if err != nil {
+		return err
+	}
+	if from < 0 || from > len(actions) {
+		return fmt.Errorf("step out of range")
+	}
+
+	seed := setup.Seed
+	replay, err := engine.NewReplay(ctx, &engine.InitGameRequest{
+		GameID:      gameID,
+		Player1:     &engine.PlayerSetup{UserID: setup.Player1ID, Deck: setup.Player1Deck},
+		Player2:     &engine.PlayerSetup{UserID: setup.Player2ID, Deck: setup.Player2Deck},
+		Seed:        &seed,
+		TimeControl: setup.TimeControl,
+	}, actions)
+	if err != nil {
+		return fmt.Errorf("failed to replay game: %w", err)
+	}
+	defer replay.Close(ctx)
+
+	current := &ReplayStepResponse{
+		GameID:          gameID,
+		TotalSteps:      len(actions),
+		GameState:       replay.State(),
+		Effects:         []EffectResult{},
+		EventsTriggered: []GameEvent{},
+	}
+	for {
+		if replay.Step() >= from {
+			if err := emit(current); err != nil {
+				return err
+			}
+		}
+		if replay.Done() {
+			return nil
+		}
+
+		step, err := replay.Next(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to replay game: %w", err)
+		}
+		current = &ReplayStepResponse{
+			GameID:          gameID,
+			Step:            replay.Step(),
+			TotalSteps:      len(actions),
+			Action:          &step.Action,
+			GameState:       step.GameState,
+			Effects:         toEffectResults(step.Effects),
+			EventsTriggered: toGameEvents(step.EventsTriggered),
+		}
+	}
+}
+
+// completeGame marks the game as completed once an action has decided it
+func (s *gameService) completeGame(ctx context.Context, gameID uuid.UUID, winCondition *engine.WinCondition) {
+	winner := *winCondition.Winner
+	if err := s.gameRepo.SetGameWinner(ctx, gameID, winner, winCondition.EndReason); err != nil {
+		if errors.Is(err, repository.ErrGameNotInProgress) {
+			// Already ended, by a surrender or on another instance, whose result stands
+			s.releaseGame(ctx, gameID)
+			return
+		}
+		logger.Error("Failed to record game result",
+			zap.String("game_id", gameID.String()),
+			zap.Error(err))
+		return
+	}
+	s.releaseGame(ctx, gameID)
+	s.reportResult(ctx, gameID, winner, winCondition.EndReason)
+
+	logger.Info("Game ended",
+		zap.String("game_id", gameID.String()),
+		zap.String("winner", winner.String()),
+		zap.String("reason", winCondition.Reason))
+}
+
+// releaseGame drops what is kept in memory for a game that has ended. Unloading it from the engine
+// stops its clock, so no timeout is reported for it afterwards.
+func (s *gameService) releaseGame(ctx context.Context, gameID uuid.UUID) {
+	s.finishSpectatorFeed(gameID)
+	s.disconnects.clearGame(gameID)
+	s.views.clearGame(gameID)
+	// Not loaded when the game ended on another instance or was settled from its stored state
+	s.gameEngine.UnloadGame(ctx, gameID)
+}
+
+// broadcastState pushes the change to the game state to every human player, each against their own view of it,
+// and queues the public view for spectators
+func (s *gameService) broadcastState(ctx context.Context, gameID uuid.UUID, gameState *models.GameState, effects []EffectResult, events []GameEvent) {
+	if s.notifier == nil || gameState == nil {
+		return
+	}
+
+	s.pushSpectatorUpdate(ctx, gameID, gameState, effects, events)
+
+	for playerID := range gameState.Players {
+		if _, isBot := s.bots.Get(playerID); isBot {
+			continue
+		}
+		s.sendGameChange(gameID, playerID, gameState, effects, events)
+	}
+}
+
+// sendGameUpdate sends one player their full view of the game state, which the next patch is made against
+func (s *gameService) sendGameUpdate(gameID uuid.UUID, playerID uuid.UUID, gameState *models.GameState, effects []EffectResult, events []GameEvent) {
+	view := engine.ViewGameState(gameState, playerID)
+	message, err := s.gameUpdateMessage(gameID, view, effects, events)
+	if err != nil {
+		logger.Error("Failed to serialize game update", zap.Error(err))
+		return
+	}
+	s.rememberView(gameID, playerID, view)
+	s.notifier.SendToUser(playerID, message)
+}
+
+func (s *gameService) gameUpdateMessage(gameID uuid.UUID, view *models.GameState, effects []EffectResult, events []GameEvent) ([]byte, error) {
+	return json.Marshal(websocket.Message{
+		Type: MessageTypeGameUpdate,
+		Payload: GameUpdate{
+			GameID:          gameID,
+			GameState:       view,
+			Effects:         effects,
+			EventsTriggered: events,
+			Spectators:      s.notifier.SpectatorCount(gameID),
+			ServerTime:      time.Now(),
+		},
+		GameID: &gameID,
+	})
+}
+
+// resyncGame replaces the engine's copy of a game with the latest saved state after a version conflict
+func (s *gameService) resyncGame(ctx context.Context, gameID uuid.UUID, conflict error) {
+	logger.Info("Game state version conflict, reloading saved state",
+		zap.String("game_id", gameID.String()),
+		zap.Error(conflict))
+
+	gameState, err := s.gameRepo.LoadGameState(ctx, gameID)
+	if err == nil {
+		err = s.loadGameStateIntoEngine(ctx, gameID, gameState)
+	}
+	if err != nil {
+		// Drop the stale copy so the next request loads the game from the database
+		logger.Error("Failed to reload game state after conflict",
+			zap.String("game_id", gameID.String()),
+			zap.Error(err))
+		s.gameEngine.UnloadGame(ctx, gameID)
+	}
+}
+
 // loadGameStateIntoEngine loads a game state from database into the engine memory
 func (s *gameService) loadGameStateIntoEngine(ctx context.Context, gameID uuid.UUID, gameState *models.GameState) error {
 	// Use the LoadGameState method from the engine interface
@@ -625,9 +1133,41 @@ func (s *gameService) modelToGameInfo(game *models.Game) *GameInfo {
 		Phase:        game.Phase,
 		ActivePlayer: game.ActivePlayer,
 		Winner:       game.Winner,
+		GameMode:        game.GameMode,
+		AllowSpectators: game.AllowSpectators,
+		SpectatorDelay:  game.SpectatorDelay,
 		StartedAt:    game.StartedAt,
 		CompletedAt:  game.CompletedAt,
 		CreatedAt:    game.CreatedAt,
 		UpdatedAt:    game.UpdatedAt,
 	}
 }
+
+func toEffectResults(effects []engine.EffectResult) []EffectResult {
+	results := make([]EffectResult, 0, len(effects))
+	for _, effect := range effects {
+		results = append(results, EffectResult{
+			Type:        effect.Type,
+			Source:      effect.Source,
+			Target:      effect.Target,
+			Value:       effect.Value,
+			Description: effect.Description,
+			Applied:     effect.Applied,
+		})
+	}
+	return results
+}
+
+func toGameEvents(events []engine.GameEvent) []GameEvent {
+	results := make([]GameEvent, 0, len(events))
+	for _, event := range events {
+		results = append(results, GameEvent{
+			Type:      event.Type,
+			Source:    event.Source,
+			Target:    event.Target,
+			Data:      event.Data,
+			Timestamp: event.Timestamp,
+		})
+	}
+	return results
+}
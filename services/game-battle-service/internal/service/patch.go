@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/logger"
+	"ua/shared/models"
+	"ua/shared/websocket"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const MessageTypeGamePatch = "GAME_PATCH"
+
+// GamePatch is pushed to a player instead of a full GameUpdate once they have a view of the game.
+// Patch is an RFC 6902 JSON Patch turning their view at FromVersion into their view at Version;
+// a client holding any other version should ask for a resync.
+type GamePatch struct {
+	GameID          uuid.UUID        `json:"game_id"`
+	FromVersion     int64            `json:"from_version"`
+	Version         int64            `json:"version"`
+	Patch           []PatchOperation `json:"patch"`
+	Effects         []EffectResult   `json:"effects"`
+	EventsTriggered []GameEvent      `json:"events_triggered"`
+	Spectators      int              `json:"spectators"`
+	ServerTime      time.Time        `json:"server_time"`
+}
+
+// PatchOperation is one RFC 6902 operation; only add, remove and replace are produced
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// sentView is the last view of a game sent to a player, decoded into generic JSON so it can be diffed
+type sentView struct {
+	version  int64
+	document interface{}
+}
+
+// playerViews holds what each player was last sent, by game then player. The map has its own lock and each view has
+// another, so sending to one player never waits on a send to another.
+type playerViews struct {
+	mu    sync.Mutex
+	views map[uuid.UUID]map[uuid.UUID]*playerView
+}
+
+// playerView is one player's view of one game. Its lock is held while a change is diffed and sent,
+// so the player gets their patches in version order.
+type playerView struct {
+	mu   sync.Mutex
+	sent *sentView
+}
+
+func newPlayerViews() *playerViews {
+	return &playerViews{views: make(map[uuid.UUID]map[uuid.UUID]*playerView)}
+}
+
+func (p *playerViews) get(gameID, playerID uuid.UUID) *playerView {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.views[gameID] == nil {
+		p.views[gameID] = make(map[uuid.UUID]*playerView)
+	}
+	view, exists := p.views[gameID][playerID]
+	if !exists {
+		view = &playerView{}
+		p.views[gameID][playerID] = view
+	}
+	return view
+}
+
+func (p *playerViews) clearGame(gameID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.views, gameID)
+}
+
+// sendGameChange sends one player the change to their view of the game state: a patch against the view they were
+// last sent, or the full view when they have none or the patch would not be smaller.
+// States older than the one the player already has are not sent, so concurrent requests cannot roll a client back.
+func (s *gameService) sendGameChange(gameID uuid.UUID, playerID uuid.UUID, gameState *models.GameState, effects []EffectResult, events []GameEvent) {
+	view := engine.ViewGameState(gameState, playerID)
+	document, err := toJSONDocument(view)
+	if err != nil {
+		logger.Error("Failed to serialize game state", zap.Error(err))
+		return
+	}
+
+	// Only this player's view stays locked while the message is published
+	playerView := s.views.get(gameID, playerID)
+	playerView.mu.Lock()
+	defer playerView.mu.Unlock()
+
+	base := playerView.sent
+	if base != nil && base.version >= view.Version {
+		return
+	}
+	playerView.sent = &sentView{version: view.Version, document: document}
+
+	update, err := s.gameUpdateMessage(gameID, view, effects, events)
+	if err != nil {
+		logger.Error("Failed to serialize game update", zap.Error(err))
+		return
+	}
+	if base == nil {
+		s.notifier.SendToUser(playerID, update)
+		return
+	}
+
+	patch, err := json.Marshal(websocket.Message{
+		Type: MessageTypeGamePatch,
+		Payload: GamePatch{
+			GameID:          gameID,
+			FromVersion:     base.version,
+			Version:         view.Version,
+			Patch:           diffJSON("", base.document, document, []PatchOperation{}),
+			Effects:         effects,
+			EventsTriggered: events,
+			Spectators:      s.notifier.SpectatorCount(gameID),
+			ServerTime:      time.Now(),
+		},
+		GameID: &gameID,
+	})
+	if err != nil {
+		logger.Error("Failed to serialize game patch", zap.Error(err))
+		return
+	}
+
+	if len(patch) < len(update) {
+		s.notifier.SendToUser(playerID, patch)
+	} else {
+		s.notifier.SendToUser(playerID, update)
+	}
+}
+
+// rememberView records a view the player received outside the WebSocket pushes, such as a resync,
+// so the next patch is made against it
+func (s *gameService) rememberView(gameID uuid.UUID, playerID uuid.UUID, view *models.GameState) {
+	if view == nil {
+		return
+	}
+	document, err := toJSONDocument(view)
+	if err != nil {
+		logger.Error("Failed to serialize game state", zap.Error(err))
+		return
+	}
+
+	playerView := s.views.get(gameID, playerID)
+	playerView.mu.Lock()
+	defer playerView.mu.Unlock()
+	if playerView.sent == nil || playerView.sent.version <= view.Version {
+		playerView.sent = &sentView{version: view.Version, document: document}
+	}
+}
+
+// toJSONDocument decodes the JSON of a value into maps, slices and json.Number, keeping numbers exact
+func toJSONDocument(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// diffJSON appends the operations turning before into after to patch. Object keys are visited in order so the same
+// change always gives the same patch.
+func diffJSON(path string, before, after interface{}, patch []PatchOperation) []PatchOperation {
+	switch beforeValue := before.(type) {
+	case map[string]interface{}:
+		if afterValue, ok := after.(map[string]interface{}); ok {
+			return diffObjects(path, beforeValue, afterValue, patch)
+		}
+	case []interface{}:
+		if afterValue, ok := after.([]interface{}); ok {
+			return diffArrays(path, beforeValue, afterValue, patch)
+		}
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return patch
+	}
+	return appendOperation(patch, "replace", path, after)
+}
+
+func diffObjects(path string, before, after map[string]interface{}, patch []PatchOperation) []PatchOperation {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, exists := before[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path + "/" + pointerEscaper.Replace(key)
+		beforeValue, inBefore := before[key]
+		afterValue, inAfter := after[key]
+		switch {
+		case !inAfter:
+			patch = appendOperation(patch, "remove", keyPath, nil)
+		case !inBefore:
+			patch = appendOperation(patch, "add", keyPath, afterValue)
+		default:
+			patch = diffJSON(keyPath, beforeValue, afterValue, patch)
+		}
+	}
+	return patch
+}
+
+// diffArrays keeps the elements both arrays start and end with, diffs the changed middle element by element
+// and adds or removes the rest, which keeps appends to logs and draws from the front of a pile small
+func diffArrays(path string, before, after []interface{}, patch []PatchOperation) []PatchOperation {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && reflect.DeepEqual(before[prefix], after[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		reflect.DeepEqual(before[len(before)-1-suffix], after[len(after)-1-suffix]) {
+		suffix++
+	}
+
+	changedBefore := before[prefix : len(before)-suffix]
+	changedAfter := after[prefix : len(after)-suffix]
+	common := min(len(changedBefore), len(changedAfter))
+
+	for i := 0; i < common; i++ {
+		patch = diffJSON(path+"/"+strconv.Itoa(prefix+i), changedBefore[i], changedAfter[i], patch)
+	}
+	for i := common; i < len(changedAfter); i++ {
+		patch = appendOperation(patch, "add", path+"/"+strconv.Itoa(prefix+i), changedAfter[i])
+	}
+	// Each removal shifts the next element into the same index
+	for i := common; i < len(changedBefore); i++ {
+		patch = appendOperation(patch, "remove", path+"/"+strconv.Itoa(prefix+common), nil)
+	}
+	return patch
+}
+
+func appendOperation(patch []PatchOperation, op string, path string, value interface{}) []PatchOperation {
+	operation := PatchOperation{Op: op, Path: path}
+	if op != "remove" {
+		// Values come from a decoded document, so they always encode
+		operation.Value, _ = json.Marshal(value)
+	}
+	return append(patch, operation)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"ua/shared/logger"
+	"ua/shared/models"
+	"ua/shared/websocket"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	MessageTypePlayerDisconnected = "PLAYER_DISCONNECTED"
+	MessageTypePlayerReconnected  = "PLAYER_RECONNECTED"
+)
+
+// presenceTimeout bounds the work done for one connect or disconnect
+const presenceTimeout = 10 * time.Second
+
+// PresenceUpdate tells a player that their opponent dropped out of or came back to the game
+type PresenceUpdate struct {
+	GameID            uuid.UUID  `json:"game_id"`
+	PlayerID          uuid.UUID  `json:"player_id"`
+	ReconnectDeadline *time.Time `json:"reconnect_deadline,omitempty"`
+}
+
+// disconnectedSeats holds the reconnect deadline of every player who dropped out of a game in progress, by game then player
+type disconnectedSeats struct {
+	mu        sync.Mutex
+	deadlines map[uuid.UUID]map[uuid.UUID]time.Time
+}
+
+func newDisconnectedSeats() *disconnectedSeats {
+	return &disconnectedSeats{deadlines: make(map[uuid.UUID]map[uuid.UUID]time.Time)}
+}
+
+func (d *disconnectedSeats) add(gameID, playerID uuid.UUID, deadline time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.deadlines[gameID] == nil {
+		d.deadlines[gameID] = make(map[uuid.UUID]time.Time)
+	}
+	d.deadlines[gameID][playerID] = deadline
+}
+
+// remove drops a seat and reports whether it was disconnected
+func (d *disconnectedSeats) remove(gameID, playerID uuid.UUID) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, exists := d.deadlines[gameID][playerID]; !exists {
+		return false
+	}
+	delete(d.deadlines[gameID], playerID)
+	if len(d.deadlines[gameID]) == 0 {
+		delete(d.deadlines, gameID)
+	}
+	return true
+}
+
+func (d *disconnectedSeats) clearGame(gameID uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.deadlines, gameID)
+}
+
+// held returns the seats waiting for their player, as game ID to player IDs
+func (d *disconnectedSeats) held() map[uuid.UUID][]uuid.UUID {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	held := make(map[uuid.UUID][]uuid.UUID)
+	for gameID, players := range d.deadlines {
+		for playerID := range players {
+			held[gameID] = append(held[gameID], playerID)
+		}
+	}
+	return held
+}
+
+// expired removes and returns the seats whose deadline has passed, as game ID to player IDs
+func (d *disconnectedSeats) expired(now time.Time) map[uuid.UUID][]uuid.UUID {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	expired := make(map[uuid.UUID][]uuid.UUID)
+	for gameID, players := range d.deadlines {
+		for playerID, deadline := range players {
+			if now.Before(deadline) {
+				continue
+			}
+			expired[gameID] = append(expired[gameID], playerID)
+			delete(players, playerID)
+		}
+		if len(players) == 0 {
+			delete(d.deadlines, gameID)
+		}
+	}
+	return expired
+}
+
+// UserDisconnected starts the reconnect window in every game the user is playing and tells their opponents
+func (s *gameService) UserDisconnected(userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+
+	games, err := s.gameRepo.GetActiveGames(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get games of disconnected player",
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+		return
+	}
+
+	for _, game := range games {
+		if game.Status != models.GameStatusInProgress {
+			continue
+		}
+		// A new connection may have opened while the games were loading
+		if s.notifier.IsConnected(userID) {
+			return
+		}
+
+		deadline := time.Now().Add(s.reconnectGrace)
+		if s.reconnectGrace > 0 {
+			s.disconnects.add(game.ID, userID, deadline)
+		}
+
+		update := &PresenceUpdate{GameID: game.ID, PlayerID: userID}
+		if s.reconnectGrace > 0 {
+			update.ReconnectDeadline = &deadline
+		}
+		s.sendPresence(opponentOf(game, userID), MessageTypePlayerDisconnected, update)
+
+		logger.Info("Player disconnected from game",
+			zap.String("game_id", game.ID.String()),
+			zap.String("player_id", userID.String()),
+			zap.Duration("reconnect_grace", s.reconnectGrace))
+	}
+}
+
+// UserConnected ends the reconnect window of a returning player and sends them the current state of each game they play
+func (s *gameService) UserConnected(userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+
+	games, err := s.gameRepo.GetActiveGames(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get games of connected player",
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+		return
+	}
+
+	for _, game := range games {
+		if game.Status != models.GameStatusInProgress {
+			continue
+		}
+		s.reconnectPlayer(ctx, game, userID)
+	}
+}
+
+// reconnectPlayer tells the opponent that the player is back, if they were away, and resyncs the player
+func (s *gameService) reconnectPlayer(ctx context.Context, game *models.Game, playerID uuid.UUID) {
+	if s.disconnects.remove(game.ID, playerID) {
+		s.sendPresence(opponentOf(game, playerID), MessageTypePlayerReconnected, &PresenceUpdate{GameID: game.ID, PlayerID: playerID})
+
+		logger.Info("Player reconnected to game",
+			zap.String("game_id", game.ID.String()),
+			zap.String("player_id", playerID.String()))
+	}
+
+	gameState, err := s.gameEngine.GetGameState(ctx, game.ID)
+	if err != nil {
+		// Not loaded on this instance, so the stored state is the latest
+		if len(game.GameState) == 0 {
+			return
+		}
+		gameState = &models.GameState{}
+		if err := json.Unmarshal(game.GameState, gameState); err != nil {
+			logger.Error("Failed to deserialize game state", zap.Error(err))
+			return
+		}
+	}
+	s.sendGameUpdate(game.ID, playerID, gameState, []EffectResult{}, []GameEvent{})
+}
+
+// expireDisconnects ends the games of players whose reconnect window has passed.
+// Players who came back through another instance are only seen here, and are reconnected instead.
+func (s *gameService) expireDisconnects(ctx context.Context) {
+	for gameID, playerIDs := range s.disconnects.held() {
+		for _, playerID := range playerIDs {
+			if !s.notifier.IsConnected(playerID) {
+				continue
+			}
+			if game, err := s.gameRepo.GetGame(ctx, gameID); err == nil {
+				s.reconnectPlayer(ctx, game, playerID)
+			}
+		}
+	}
+
+	for gameID, playerIDs := range s.disconnects.expired(time.Now()) {
+		for _, playerID := range playerIDs {
+			// The player came back but the connect was handled before the disconnect
+			if s.notifier.IsConnected(playerID) {
+				if game, err := s.gameRepo.GetGame(ctx, gameID); err == nil {
+					s.reconnectPlayer(ctx, game, playerID)
+				}
+				continue
+			}
+
+			if _, err := s.playAction(ctx, &PlayActionRequest{
+				GameID:     gameID,
+				PlayerID:   playerID,
+				ActionType: models.ActionTypeConnectionLost,
+			}); err != nil {
+				logger.Error("Failed to end game after lost connection",
+					zap.String("game_id", gameID.String()),
+					zap.String("player_id", playerID.String()),
+					zap.Error(err))
+				continue
+			}
+
+			logger.Info("Player lost connection to game",
+				zap.String("game_id", gameID.String()),
+				zap.String("player_id", playerID.String()))
+			break
+		}
+	}
+}
+
+func (s *gameService) sendPresence(userID uuid.UUID, messageType string, update *PresenceUpdate) {
+	message, err := json.Marshal(websocket.Message{
+		Type:    messageType,
+		Payload: update,
+		GameID:  &update.GameID,
+	})
+	if err != nil {
+		logger.Error("Failed to serialize presence update", zap.Error(err))
+		return
+	}
+	s.notifier.SendToUser(userID, message)
+}
+
+func opponentOf(game *models.Game, playerID uuid.UUID) uuid.UUID {
+	if game.Player1ID == playerID {
+		return game.Player2ID
+	}
+	return game.Player1ID
+}
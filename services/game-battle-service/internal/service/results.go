@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ua/shared/events"
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// resultResendInterval is how often RunTimers publishes again the results that could not be published
+	resultResendInterval = time.Minute
+	// resultResendAfter is how long after a game ended its result is left to the request that ended it
+	resultResendAfter = time.Minute
+	// resultResendBatch caps the results published again at a time
+	resultResendBatch = 100
+)
+
+// ResultPublisher hands the outcome of finished games to the result service
+type ResultPublisher interface {
+	PublishGameCompleted(ctx context.Context, event *events.GameCompleted) error
+}
+
+// reportResult publishes the result of a game that has just been given a winner, with the models.EndReason* it ended by.
+// A result that cannot be published stays pending in the games table and is published again by resendResults.
+func (s *gameService) reportResult(ctx context.Context, gameID uuid.UUID, winner uuid.UUID, endReason string) {
+	if s.results == nil {
+		return
+	}
+
+	game, err := s.gameRepo.GetGame(ctx, gameID)
+	if err != nil {
+		logger.Error("Failed to load finished game for its result",
+			zap.String("game_id", gameID.String()),
+			zap.Error(err))
+		return
+	}
+
+	game.Winner = &winner
+	game.EndReason = endReason
+	s.publishResult(ctx, game)
+}
+
+// resendResults publishes the results that are still pending a while after their game ended
+func (s *gameService) resendResults(ctx context.Context) {
+	if s.results == nil {
+		return
+	}
+
+	games, err := s.gameRepo.GetPendingResults(ctx, time.Now().Add(-resultResendAfter), resultResendBatch)
+	if err != nil {
+		logger.Error("Failed to get pending game results", zap.Error(err))
+		return
+	}
+
+	for _, game := range games {
+		if ctx.Err() != nil {
+			return
+		}
+		s.publishResult(ctx, game)
+	}
+}
+
+// publishResult publishes the result of a completed game and marks it as reported.
+// Practice games against a bot are not published, so they never count toward stats or rank points.
+func (s *gameService) publishResult(ctx context.Context, game *models.Game) {
+	for _, playerID := range []uuid.UUID{game.Player1ID, game.Player2ID} {
+		if _, isBot := s.bots.Get(playerID); isBot {
+			s.markResultReported(ctx, game.ID)
+			return
+		}
+	}
+
+	startedAt := game.CreatedAt
+	if game.StartedAt != nil {
+		startedAt = *game.StartedAt
+	}
+	completedAt := time.Now()
+	if game.CompletedAt != nil {
+		completedAt = *game.CompletedAt
+	}
+
+	event := &events.GameCompleted{
+		GameID:      game.ID,
+		Player1ID:   game.Player1ID,
+		Player2ID:   game.Player2ID,
+		Winner:      game.Winner,
+		EndReason:   game.EndReason,
+		TotalTurns:  max(game.CurrentTurn, 1),
+		Duration:    max(int(completedAt.Sub(startedAt).Seconds()), 1),
+		GameMode:    game.GameMode,
+		CompletedAt: completedAt,
+	}
+
+	if err := s.results.PublishGameCompleted(ctx, event); err != nil {
+		logger.Error("Failed to publish game result, it will be published again",
+			zap.String("game_id", game.ID.String()),
+			zap.Error(err))
+		return
+	}
+	s.markResultReported(ctx, game.ID)
+
+	logger.Info("Game result published",
+		zap.String("game_id", game.ID.String()),
+		zap.String("end_reason", event.EndReason))
+}
+
+// markResultReported clears the pending result; if that fails the result is published again, which the result
+// service ignores
+func (s *gameService) markResultReported(ctx context.Context, gameID uuid.UUID) {
+	if err := s.gameRepo.MarkResultReported(ctx, gameID); err != nil {
+		logger.Error("Failed to mark game result reported",
+			zap.String("game_id", gameID.String()),
+			zap.Error(err))
+	}
+}
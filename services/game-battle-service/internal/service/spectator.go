@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/logger"
+	"ua/shared/models"
+	"ua/shared/websocket"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const MessageTypeSpectatorUpdate = "SPECTATOR_UPDATE"
+
+// maxSpectatableGames caps the spectatable games list
+const maxSpectatableGames = 100
+
+// SpectatorUpdate is pushed to the spectators of a game. The state is the public view,
+// SpectatorDelay actions behind the game; it is omitted until the game is that many actions in.
+type SpectatorUpdate struct {
+	GameID          uuid.UUID         `json:"game_id"`
+	GameState       *models.GameState `json:"game_state,omitempty"`
+	Effects         []EffectResult    `json:"effects"`
+	EventsTriggered []GameEvent       `json:"events_triggered"`
+	SpectatorDelay  int               `json:"spectator_delay"`
+	Spectators      int               `json:"spectators"`
+}
+
+type SpectatableGame struct {
+	GameInfo
+	Spectators int `json:"spectators"`
+}
+
+type SpectatableGamesResponse struct {
+	Games []SpectatableGame `json:"games"`
+}
+
+// spectatorFeed holds what the spectators of one game have seen and what they will see once the delay has passed
+type spectatorFeed struct {
+	allowed bool
+	delay   int
+	pending []*SpectatorUpdate // oldest first
+	current *SpectatorUpdate
+}
+
+// push queues an update and returns the updates that are now due, oldest first
+func (f *spectatorFeed) push(update *SpectatorUpdate) []*SpectatorUpdate {
+	if !f.allowed {
+		return nil
+	}
+
+	f.pending = append(f.pending, update)
+	// Concurrent requests can report their states out of order
+	sort.SliceStable(f.pending, func(i, j int) bool {
+		return f.pending[i].GameState.Version < f.pending[j].GameState.Version
+	})
+
+	var due []*SpectatorUpdate
+	for len(f.pending) > f.delay {
+		f.current = f.pending[0]
+		f.pending = f.pending[1:]
+		due = append(due, f.current)
+	}
+	return due
+}
+
+// spectatorFeeds keeps a feed for every game this instance has seen actions for
+type spectatorFeeds struct {
+	mu    sync.Mutex
+	feeds map[uuid.UUID]*spectatorFeed
+}
+
+func newSpectatorFeeds() *spectatorFeeds {
+	return &spectatorFeeds{feeds: make(map[uuid.UUID]*spectatorFeed)}
+}
+
+// spectatorFeed returns the feed of a game, loading its spectator settings the first time
+func (s *gameService) spectatorFeed(ctx context.Context, gameID uuid.UUID) (*spectatorFeed, error) {
+	s.spectators.mu.Lock()
+	feed, exists := s.spectators.feeds[gameID]
+	s.spectators.mu.Unlock()
+	if exists {
+		return feed, nil
+	}
+
+	game, err := s.gameRepo.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.spectators.mu.Lock()
+	defer s.spectators.mu.Unlock()
+	if feed, exists := s.spectators.feeds[gameID]; exists {
+		return feed, nil
+	}
+	ended := game.Status == models.GameStatusCompleted || game.Status == models.GameStatusAbandoned
+	feed = &spectatorFeed{allowed: game.AllowSpectators && !ended, delay: game.SpectatorDelay}
+	s.spectators.feeds[gameID] = feed
+	return feed, nil
+}
+
+// pushSpectatorUpdate queues the public view of a new state and sends spectators whatever the delay now lets through
+func (s *gameService) pushSpectatorUpdate(ctx context.Context, gameID uuid.UUID, gameState *models.GameState, effects []EffectResult, events []GameEvent) {
+	feed, err := s.spectatorFeed(ctx, gameID)
+	if err != nil {
+		logger.Error("Failed to load spectator settings",
+			zap.String("game_id", gameID.String()),
+			zap.Error(err))
+		return
+	}
+
+	s.spectators.mu.Lock()
+	due := feed.push(&SpectatorUpdate{
+		GameID:          gameID,
+		GameState:       engine.ViewGameState(gameState, uuid.Nil),
+		Effects:         effects,
+		EventsTriggered: events,
+		SpectatorDelay:  feed.delay,
+	})
+	s.spectators.mu.Unlock()
+
+	s.sendSpectatorUpdates(gameID, due)
+}
+
+// finishSpectatorFeed shows spectators the rest of a game that has ended and drops its feed
+func (s *gameService) finishSpectatorFeed(gameID uuid.UUID) {
+	s.spectators.mu.Lock()
+	feed, exists := s.spectators.feeds[gameID]
+	delete(s.spectators.feeds, gameID)
+	s.spectators.mu.Unlock()
+
+	if exists && feed.allowed {
+		s.sendSpectatorUpdates(gameID, feed.pending)
+	}
+}
+
+func (s *gameService) sendSpectatorUpdates(gameID uuid.UUID, updates []*SpectatorUpdate) {
+	if s.notifier == nil || len(updates) == 0 {
+		return
+	}
+
+	spectators := s.notifier.SpectatorCount(gameID)
+	for _, update := range updates {
+		// Updates stay in the feed as the current view, so the count is set on a copy
+		payload := *update
+		payload.Spectators = spectators
+		message, err := json.Marshal(websocket.Message{
+			Type:    MessageTypeSpectatorUpdate,
+			Payload: payload,
+			GameID:  &gameID,
+		})
+		if err != nil {
+			logger.Error("Failed to serialize spectator update", zap.Error(err))
+			return
+		}
+		s.notifier.BroadcastToSpectators(gameID, message)
+	}
+}
+
+// GetSpectatorView checks that the user may watch the game and returns what a new spectator sees first
+func (s *gameService) GetSpectatorView(ctx context.Context, gameID uuid.UUID, userID uuid.UUID) (*SpectatorUpdate, error) {
+	game, err := s.gameRepo.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("game not found")
+	}
+	if game.Status != models.GameStatusInProgress {
+		return nil, fmt.Errorf("game is not in progress")
+	}
+	if !game.AllowSpectators {
+		return nil, fmt.Errorf("game is not open to spectators")
+	}
+	if userID == game.Player1ID || userID == game.Player2ID {
+		return nil, fmt.Errorf("players cannot spectate their own game")
+	}
+
+	feed, err := s.spectatorFeed(ctx, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game: %w", err)
+	}
+
+	s.spectators.mu.Lock()
+	current := feed.current
+	s.spectators.mu.Unlock()
+
+	// The count includes the spectator who is joining
+	spectators := 1
+	if s.notifier != nil {
+		spectators += s.notifier.SpectatorCount(gameID)
+	}
+
+	if current != nil {
+		snapshot := *current
+		snapshot.Spectators = spectators
+		return &snapshot, nil
+	}
+
+	// Nothing was shown to spectators on this instance yet: build the delayed state from the game itself
+	snapshot := &SpectatorUpdate{
+		GameID:          gameID,
+		Effects:         []EffectResult{},
+		EventsTriggered: []GameEvent{},
+		SpectatorDelay:  game.SpectatorDelay,
+		Spectators:      spectators,
+	}
+
+	gameState, err := s.gameEngine.GetGameState(ctx, gameID)
+	if err != nil {
+		if len(game.GameState) == 0 {
+			return snapshot, nil
+		}
+		gameState = &models.GameState{}
+		if err := json.Unmarshal(game.GameState, gameState); err != nil {
+			return nil, fmt.Errorf("failed to load game state: %w", err)
+		}
+	}
+
+	if game.SpectatorDelay > 0 {
+		version := gameState.Version - int64(game.SpectatorDelay)
+		if version < 1 {
+			return snapshot, nil
+		}
+		if gameState, err = s.RebuildGameState(ctx, gameID, version); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot.GameState = engine.ViewGameState(gameState, uuid.Nil)
+	return snapshot, nil
+}
+
+func (s *gameService) GetSpectatableGames(ctx context.Context) (*SpectatableGamesResponse, error) {
+	games, err := s.gameRepo.GetSpectatableGames(ctx, maxSpectatableGames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spectatable games: %w", err)
+	}
+
+	response := &SpectatableGamesResponse{Games: []SpectatableGame{}}
+	for _, game := range games {
+		spectatable := SpectatableGame{GameInfo: *s.modelToGameInfo(game)}
+		if s.notifier != nil {
+			spectatable.Spectators = s.notifier.SpectatorCount(game.ID)
+		}
+		response.Games = append(response.Games, spectatable)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"ua/services/game-battle-service/internal/engine"
+	"ua/shared/config"
+	"ua/shared/logger"
+	"ua/shared/models"
+
+	"go.uber.org/zap"
+)
+
+// timerInterval is how often RunTimers looks for players who ran out of time
+const timerInterval = time.Second
+
+// defaultTimeControls are the clocks each game mode plays with; friend games are untimed
+var defaultTimeControls = map[string]models.TimeControl{
+	models.MatchModeRanked: {TurnSeconds: 90, ReserveSeconds: 300, MaxTimeouts: 3},
+	models.MatchModeCasual: {TurnSeconds: 120, ReserveSeconds: 600, MaxTimeouts: 3},
+	models.MatchModeFriend: {},
+}
+
+// timeControlFor returns the time control of a game mode, or nil when games of the mode are untimed.
+// The defaults can be overridden per mode with <MODE>_TURN_SECONDS, <MODE>_RESERVE_SECONDS and <MODE>_MAX_TIMEOUTS;
+// a mode without turn or reserve time is untimed.
+func timeControlFor(gameMode string) *models.TimeControl {
+	defaults := defaultTimeControls[gameMode]
+	timeControl := &models.TimeControl{
+		TurnSeconds:    config.GetEnvInt(gameMode+"_TURN_SECONDS", defaults.TurnSeconds),
+		ReserveSeconds: config.GetEnvInt(gameMode+"_RESERVE_SECONDS", defaults.ReserveSeconds),
+		MaxTimeouts:    config.GetEnvInt(gameMode+"_MAX_TIMEOUTS", defaults.MaxTimeouts),
+	}
+	if timeControl.TurnSeconds <= 0 && timeControl.ReserveSeconds <= 0 {
+		return nil
+	}
+	return timeControl
+}
+
+// RunTimers submits a TIMEOUT for every player whose clock has run out, answers every decision left past its
+// deadline with its default choices, timed game or not, and ends the games of players
+// whose reconnect window has passed, until ctx is cancelled. Only games loaded into this instance's engine are timed.
+// Every resultResendInterval it also publishes again the results of finished games that could not be published.
+func (s *gameService) RunTimers(ctx context.Context) {
+	ticker := time.NewTicker(timerInterval)
+	defer ticker.Stop()
+	resendTicker := time.NewTicker(resultResendInterval)
+	defer resendTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-resendTicker.C:
+			s.resendResults(ctx)
+			continue
+		case <-ticker.C:
+		}
+
+		for _, expired := range s.gameEngine.ExpiredClocks(ctx) {
+			s.timeoutPlayer(ctx, expired)
+		}
+		for _, expired := range s.gameEngine.ExpiredDecisions(ctx) {
+			s.expireDecision(ctx, expired)
+		}
+		s.expireDisconnects(ctx)
+	}
+}
+
+// timeoutPlayer plays the TIMEOUT through the regular action path, so it is saved, logged and broadcast like any action
+func (s *gameService) timeoutPlayer(ctx context.Context, expired engine.ExpiredClock) {
+	if _, err := s.playAction(ctx, &PlayActionRequest{
+		GameID:     expired.GameID,
+		PlayerID:   expired.PlayerID,
+		ActionType: models.ActionTypeTimeout,
+	}); err != nil {
+		logger.Error("Failed to time out player",
+			zap.String("game_id", expired.GameID.String()),
+			zap.String("player_id", expired.PlayerID.String()),
+			zap.Error(err))
+		return
+	}
+
+	logger.Info("Player timed out",
+		zap.String("game_id", expired.GameID.String()),
+		zap.String("player_id", expired.PlayerID.String()))
+
+	// The turn may have passed to a bot
+	s.scheduleBots(expired.GameID)
+}
+
+// expireDecision answers the decision with its default choices through the regular action path,
+// so the answer is saved and replayed like one the player sent
+func (s *gameService) expireDecision(ctx context.Context, expired engine.ExpiredDecision) {
+	actionData, err := json.Marshal(models.ActionData{
+		DecisionID: &expired.DecisionID,
+		Choices:    expired.Choices,
+	})
+	if err != nil {
+		logger.Error("Failed to encode expired decision", zap.Error(err))
+		return
+	}
+
+	if _, err := s.playAction(ctx, &PlayActionRequest{
+		GameID:     expired.GameID,
+		PlayerID:   expired.PlayerID,
+		ActionType: models.ActionTypeResolveDecision,
+		ActionData: actionData,
+	}); err != nil {
+		logger.Error("Failed to resolve expired decision",
+			zap.String("game_id", expired.GameID.String()),
+			zap.String("player_id", expired.PlayerID.String()),
+			zap.String("decision_id", expired.DecisionID.String()),
+			zap.Error(err))
+		return
+	}
+
+	logger.Info("Decision expired",
+		zap.String("game_id", expired.GameID.String()),
+		zap.String("player_id", expired.PlayerID.String()),
+		zap.String("decision_id", expired.DecisionID.String()))
+
+	// The turn may have passed to a bot
+	s.scheduleBots(expired.GameID)
+}
@@ -13,14 +13,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
 	_ "ua/services/game-result-service/docs" // Swagger docs
 	"ua/services/game-result-service/internal/handler"
 	"ua/services/game-result-service/internal/repository"
 	"ua/services/game-result-service/internal/service"
 	"ua/shared/config"
 	"ua/shared/database"
+	"ua/shared/events"
 	"ua/shared/logger"
 	"ua/shared/middleware"
+	"ua/shared/redis"
 )
 
 // @title UA Game Result Service API
@@ -52,16 +55,41 @@ func main() {
 		log.Fatal("Failed to initialize logger:", err)
 	}
 
+	if cfg.GameEventsSecret == "" {
+		log.Fatal("GAME_EVENTS_SECRET must be set")
+	}
+
 	db, err := database.NewPostgresDB(cfg.PostgresURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	defer db.Close()
 
+	redisClient, err := redis.NewRedisClient(cfg.RedisURL)
+	if err != nil {
+		log.Fatal("Failed to connect to Redis:", err)
+	}
+	defer redisClient.Close()
+
 	resultRepo := repository.NewResultRepository(db)
 	resultService := service.NewResultService(resultRepo)
 	resultHandler := handler.NewResultHandler(resultService)
 
+	// Results of finished games come from the battle service, signed with the game events secret both services share.
+	// The consumer name is the host name, so a restarted instance picks up the events it had not finished.
+	consumerName, err := os.Hostname()
+	if err != nil {
+		consumerName = "game-result-service"
+	}
+	consumer := events.NewConsumer(redisClient, cfg.GameEventsSecret, "game-result-service", consumerName)
+	consumeCtx, stopConsuming := context.WithCancel(context.Background())
+	defer stopConsuming()
+	go func() {
+		if err := consumer.Consume(consumeCtx, resultService.RecordCompletedGame); err != nil {
+			logger.Error("Game result consumer stopped", zap.Error(err))
+		}
+	}()
+
 	router := setupRouter(cfg, resultHandler)
 
 	srv := &http.Server{
@@ -81,6 +109,7 @@ func main() {
 	<-quit
 
 	logger.Info("Game Result Service shutting down...")
+	stopConsuming()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -125,8 +154,8 @@ func setupRouter(cfg *config.Config, resultHandler *handler.ResultHandler) *gin.
 		api.GET("/results/player/:userId/achievements", resultHandler.GetPlayerAchievements)
 		api.GET("/results/player/:userId/history", resultHandler.GetMatchHistory)
 
+		// Results are recorded from the battle service's game completed events, never posted by clients
 		api.Use(middleware.AuthMiddleware(cfg.JWTSecret))
-		api.POST("/results", resultHandler.RecordResult)
 		api.POST("/analytics", resultHandler.GetAnalytics)
 		api.GET("/analytics/overview", resultHandler.GetAnalyticsOverview)
 		api.POST("/results/rankings/update", resultHandler.UpdateRankings)
@@ -21,33 +21,6 @@ func NewResultHandler(resultService service.ResultService) *ResultHandler {
 	}
 }
 
-// @Summary Record game result
-// @Description Record the result of a completed game
-// @Tags results
-// @Accept json
-// @Produce json
-// @Param result body service.RecordResultRequest true "Game result data"
-// @Success 201 {object} utils.Response{data=service.RecordResultResponse}
-// @Failure 400 {object} utils.Response
-// @Failure 500 {object} utils.Response
-// @Security BearerAuth
-// @Router /api/v1/results [post]
-func (h *ResultHandler) RecordResult(c *gin.Context) {
-	var req service.RecordResultRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
-		return
-	}
-
-	response, err := h.resultService.RecordGameResult(c.Request.Context(), &req)
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to record game result: "+err.Error())
-		return
-	}
-
-	utils.CreatedResponse(c, response)
-}
-
 // @Summary Get player statistics
 // @Description Get comprehensive statistics for a player
 // @Tags results
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,7 +12,11 @@ import (
 	"ua/shared/models"
 )
 
+// ErrResultExists is returned by CreateResult when the game already has a result
+var ErrResultExists = errors.New("game result already recorded")
+
 type ResultRepository interface {
+	// CreateResult records a game result and updates both players' stats; a game is only ever recorded once
 	CreateResult(ctx context.Context, result *models.GameResult) error
 	GetResult(ctx context.Context, gameID uuid.UUID) (*models.GameResult, error)
 	GetPlayerResults(ctx context.Context, playerID uuid.UUID, page, limit int) ([]*models.GameResult, int64, error)
@@ -120,18 +125,24 @@ func (r *resultRepository) CreateResult(ctx context.Context, result *models.Game
 
 	query := `
 		INSERT INTO game_results (id, game_id, player1_id, player2_id, winner, 
-								 game_duration, total_turns, end_reason, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+								 game_duration, total_turns, end_reason, game_mode, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (game_id) DO NOTHING`
 
-	_, err = tx.ExecContext(ctx, query,
+	res, err := tx.ExecContext(ctx, query,
 		result.ID, result.GameID, result.Player1ID, result.Player2ID,
 		result.Winner, result.GameDuration, result.TotalTurns,
-		result.EndReason, result.CompletedAt)
+		result.EndReason, result.GameMode, result.CompletedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create game result: %w", err)
 	}
 
+	// The stats were already updated along with the existing result
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return ErrResultExists
+	}
+
 	if err := r.updatePlayerStatsInTx(ctx, tx, result.Player1ID, result); err != nil {
 		return fmt.Errorf("failed to update player1 stats: %w", err)
 	}
@@ -146,14 +157,14 @@ func (r *resultRepository) CreateResult(ctx context.Context, result *models.Game
 func (r *resultRepository) GetResult(ctx context.Context, gameID uuid.UUID) (*models.GameResult, error) {
 	query := `
 		SELECT id, game_id, player1_id, player2_id, winner, game_duration,
-			   total_turns, end_reason, completed_at
+			   total_turns, end_reason, game_mode, completed_at
 		FROM game_results WHERE game_id = $1`
 
 	result := &models.GameResult{}
 	err := r.db.QueryRowContext(ctx, query, gameID).Scan(
 		&result.ID, &result.GameID, &result.Player1ID, &result.Player2ID,
 		&result.Winner, &result.GameDuration, &result.TotalTurns,
-		&result.EndReason, &result.CompletedAt)
+		&result.EndReason, &result.GameMode, &result.CompletedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("game result not found")
@@ -179,7 +190,7 @@ func (r *resultRepository) GetPlayerResults(ctx context.Context, playerID uuid.U
 	offset := (page - 1) * limit
 	query := `
 		SELECT id, game_id, player1_id, player2_id, winner, game_duration,
-			   total_turns, end_reason, completed_at
+			   total_turns, end_reason, game_mode, completed_at
 		FROM game_results 
 		WHERE player1_id = $1 OR player2_id = $1
 		ORDER BY completed_at DESC
@@ -197,7 +208,7 @@ func (r *resultRepository) GetPlayerResults(ctx context.Context, playerID uuid.U
 		err := rows.Scan(
 			&result.ID, &result.GameID, &result.Player1ID, &result.Player2ID,
 			&result.Winner, &result.GameDuration, &result.TotalTurns,
-			&result.EndReason, &result.CompletedAt)
+			&result.EndReason, &result.GameMode, &result.CompletedAt)
 		if err != nil {
 			continue
 		}
@@ -410,7 +421,7 @@ func (r *resultRepository) GetMatchHistory(ctx context.Context, playerID uuid.UU
 	query := `
 		SELECT 
 			gr.id, gr.game_id, gr.player1_id, gr.player2_id, gr.winner,
-			gr.game_duration, gr.total_turns, gr.end_reason, gr.completed_at,
+			gr.game_duration, gr.total_turns, gr.end_reason, gr.game_mode, gr.completed_at,
 			CASE 
 				WHEN gr.player1_id = $1 THEN u2.id ELSE u1.id 
 			END as opponent_id,
@@ -449,7 +460,7 @@ func (r *resultRepository) GetMatchHistory(ctx context.Context, playerID uuid.UU
 			&entry.GameResult.Player1ID, &entry.GameResult.Player2ID,
 			&entry.GameResult.Winner, &entry.GameResult.GameDuration,
 			&entry.GameResult.TotalTurns, &entry.GameResult.EndReason,
-			&entry.GameResult.CompletedAt,
+			&entry.GameResult.GameMode, &entry.GameResult.CompletedAt,
 			&opponentID, &entry.Opponent.Username, &entry.Opponent.DisplayName,
 			&entry.Opponent.AvatarURL)
 
@@ -2,18 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"ua/services/game-result-service/internal/repository"
+	"ua/shared/events"
 	"ua/shared/logger"
 	"ua/shared/models"
 )
 
 type ResultService interface {
 	RecordGameResult(ctx context.Context, req *RecordResultRequest) (*RecordResultResponse, error)
+	// RecordCompletedGame records a game reported by the battle service; a game that already has a result is skipped
+	RecordCompletedGame(ctx context.Context, event *events.GameCompleted) error
 	GetPlayerStats(ctx context.Context, playerID uuid.UUID) (*PlayerStatsResponse, error)
 	GetGameResult(ctx context.Context, gameID uuid.UUID) (*models.GameResult, error)
 	GetLeaderboard(ctx context.Context, req *LeaderboardRequest) (*LeaderboardResponse, error)
@@ -149,6 +153,9 @@ func (s *resultService) RecordGameResult(ctx context.Context, req *RecordResultR
 	if req.CompletedAt.IsZero() {
 		req.CompletedAt = time.Now()
 	}
+	if req.GameMode == "" {
+		req.GameMode = models.MatchModeRanked
+	}
 
 	result := &models.GameResult{
 		ID:           uuid.New(),
@@ -159,6 +166,7 @@ func (s *resultService) RecordGameResult(ctx context.Context, req *RecordResultR
 		GameDuration: req.GameDuration,
 		TotalTurns:   req.TotalTurns,
 		EndReason:    req.EndReason,
+		GameMode:     req.GameMode,
 		CompletedAt:  req.CompletedAt,
 	}
 
@@ -206,6 +214,34 @@ func (s *resultService) RecordGameResult(ctx context.Context, req *RecordResultR
 	return response, nil
 }
 
+func (s *resultService) RecordCompletedGame(ctx context.Context, event *events.GameCompleted) error {
+	req := &RecordResultRequest{
+		GameID:       event.GameID,
+		Player1ID:    event.Player1ID,
+		Player2ID:    event.Player2ID,
+		Winner:       event.Winner,
+		GameDuration: event.Duration,
+		TotalTurns:   event.TotalTurns,
+		EndReason:    event.EndReason,
+		GameMode:     event.GameMode,
+		CompletedAt:  event.CompletedAt,
+	}
+	// Retrying would never make an invalid result valid
+	if err := s.validateRecordRequest(req); err != nil {
+		logger.Error("Dropping invalid game result",
+			zap.String("game_id", event.GameID.String()),
+			zap.Error(err))
+		return nil
+	}
+
+	_, err := s.RecordGameResult(ctx, req)
+	if errors.Is(err, repository.ErrResultExists) {
+		logger.Info("Game result already recorded", zap.String("game_id", event.GameID.String()))
+		return nil
+	}
+	return err
+}
+
 func (s *resultService) GetPlayerStats(ctx context.Context, playerID uuid.UUID) (*PlayerStatsResponse, error) {
 	stats, err := s.repo.GetPlayerStats(ctx, playerID)
 	if err != nil {
@@ -0,0 +1,280 @@
+package events
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ua/shared/logger"
+	"ua/shared/redis"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// GameCompletedStream is the Redis stream the battle service publishes finished games to
+const GameCompletedStream = "stream:game_completed"
+
+// GameCompletedDeadLetterStream holds the events consumers gave up on, with the error of their last attempt
+const GameCompletedDeadLetterStream = "stream:game_completed:dead"
+
+const (
+	// maxStreamLength roughly caps the stream; consumers are expected to keep up long before it is reached
+	maxStreamLength = 100000
+	readBlock       = 5 * time.Second
+	readCount       = 50
+	// retryDelay is how long a consumer waits after a failed event before reading it again
+	retryDelay = 5 * time.Second
+	// maxDeliveries is how many times an event is handed to a failing handler before it is dead-lettered
+	maxDeliveries = 5
+)
+
+// GameCompleted is the authoritative outcome of a finished game, as recorded by the battle service.
+// Consumers key it by GameID: the same game may be delivered more than once but must only be counted once.
+type GameCompleted struct {
+	GameID      uuid.UUID  `json:"game_id"`
+	Player1ID   uuid.UUID  `json:"player1_id"`
+	Player2ID   uuid.UUID  `json:"player2_id"`
+	Winner      *uuid.UUID `json:"winner"`
+	EndReason   string     `json:"end_reason"`
+	TotalTurns  int        `json:"total_turns"`
+	Duration    int        `json:"duration"` // in seconds
+	GameMode    string     `json:"game_mode"`
+	CompletedAt time.Time  `json:"completed_at"`
+}
+
+// Publisher adds signed GameCompleted events to the stream
+type Publisher struct {
+	client *redis.Client
+	secret []byte
+}
+
+func NewPublisher(client *redis.Client, secret string) *Publisher {
+	return &Publisher{
+		client: client,
+		secret: []byte(secret),
+	}
+}
+
+func (p *Publisher) PublishGameCompleted(ctx context.Context, event *GameCompleted) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	err = p.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: GameCompletedStream,
+		MaxLen: maxStreamLength,
+		Approx: true,
+		Values: map[string]interface{}{
+			"payload":   payload,
+			"signature": sign(p.secret, payload),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// Consumer reads GameCompleted events as one member of a consumer group, so each event goes to one instance
+type Consumer struct {
+	client   *redis.Client
+	secret   []byte
+	group    string
+	consumer string
+}
+
+// NewConsumer creates a consumer. The consumer name must stay the same across restarts,
+// so events it read but never finished are picked up again.
+func NewConsumer(client *redis.Client, secret string, group string, consumer string) *Consumer {
+	return &Consumer{
+		client:   client,
+		secret:   []byte(secret),
+		group:    group,
+		consumer: consumer,
+	}
+}
+
+// Consume passes every event to handle until ctx is cancelled. An event is acknowledged once handle succeeds;
+// after an error it is read again later, and after maxDeliveries failed deliveries it is moved to
+// GameCompletedDeadLetterStream so the events behind it are not held up. Events with a bad signature are logged and dropped.
+func (c *Consumer) Consume(ctx context.Context, handle func(ctx context.Context, event *GameCompleted) error) error {
+	err := c.client.XGroupCreateMkStream(ctx, GameCompletedStream, c.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	// Events this consumer read before a restart or a failure come first, then new ones
+	pending := true
+	for ctx.Err() == nil {
+		start, block := ">", readBlock
+		if pending {
+			start, block = "0", -1
+		}
+
+		streams, err := c.client.XReadGroup(ctx, &goredis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.consumer,
+			Streams:  []string{GameCompletedStream, start},
+			Count:    readCount,
+			Block:    block,
+		}).Result()
+		if err != nil && !errors.Is(err, goredis.Nil) {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Error("Failed to read game events", zap.Error(err))
+			wait(ctx, retryDelay)
+			continue
+		}
+
+		var messages []goredis.XMessage
+		for _, stream := range streams {
+			messages = append(messages, stream.Messages...)
+		}
+		if pending && len(messages) == 0 {
+			pending = false
+			continue
+		}
+
+		for _, message := range messages {
+			if err := c.process(ctx, message, handle); err != nil {
+				logger.Error("Failed to handle game event",
+					zap.String("message_id", message.ID),
+					zap.Error(err))
+				if c.deadLetter(ctx, message, err) {
+					continue
+				}
+				// Left unacknowledged, so it is read again with the pending events
+				pending = true
+				wait(ctx, retryDelay)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Consumer) process(ctx context.Context, message goredis.XMessage, handle func(ctx context.Context, event *GameCompleted) error) error {
+	payload, _ := message.Values["payload"].(string)
+	signature, _ := message.Values["signature"].(string)
+
+	if !verify(c.secret, []byte(payload), signature) {
+		logger.Error("Dropping game event with invalid signature", zap.String("message_id", message.ID))
+		return c.ack(ctx, message.ID)
+	}
+
+	var event GameCompleted
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		logger.Error("Dropping malformed game event",
+			zap.String("message_id", message.ID),
+			zap.Error(err))
+		return c.ack(ctx, message.ID)
+	}
+
+	if err := handle(ctx, &event); err != nil {
+		return err
+	}
+	return c.ack(ctx, message.ID)
+}
+
+// deadLetter moves the event to the dead-letter stream once it has been delivered maxDeliveries times and reports
+// whether it did. Otherwise the event is claimed again, which counts the coming retry as a delivery.
+func (c *Consumer) deadLetter(ctx context.Context, message goredis.XMessage, handleErr error) bool {
+	pending, err := c.client.XPendingExt(ctx, &goredis.XPendingExtArgs{
+		Stream: GameCompletedStream,
+		Group:  c.group,
+		Start:  message.ID,
+		End:    message.ID,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		logger.Error("Failed to count deliveries of game event",
+			zap.String("message_id", message.ID),
+			zap.Error(err))
+		return false
+	}
+
+	if pending[0].RetryCount < maxDeliveries {
+		if err := c.client.XClaim(ctx, &goredis.XClaimArgs{
+			Stream:   GameCompletedStream,
+			Group:    c.group,
+			Consumer: c.consumer,
+			Messages: []string{message.ID},
+		}).Err(); err != nil {
+			logger.Error("Failed to count retry of game event",
+				zap.String("message_id", message.ID),
+				zap.Error(err))
+		}
+		return false
+	}
+
+	values := map[string]interface{}{
+		"message_id": message.ID,
+		"error":      handleErr.Error(),
+	}
+	for key, value := range message.Values {
+		values[key] = value
+	}
+	if err := c.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: GameCompletedDeadLetterStream,
+		MaxLen: maxStreamLength,
+		Approx: true,
+		Values: values,
+	}).Err(); err != nil {
+		logger.Error("Failed to dead-letter game event",
+			zap.String("message_id", message.ID),
+			zap.Error(err))
+		return false
+	}
+	if err := c.ack(ctx, message.ID); err != nil {
+		logger.Error("Failed to acknowledge dead-lettered game event",
+			zap.String("message_id", message.ID),
+			zap.Error(err))
+		return false
+	}
+
+	logger.Error("Moved game event to the dead-letter stream",
+		zap.String("message_id", message.ID),
+		zap.Int64("deliveries", pending[0].RetryCount))
+	return true
+}
+
+func (c *Consumer) ack(ctx context.Context, messageID string) error {
+	if err := c.client.XAck(ctx, GameCompletedStream, c.group, messageID).Err(); err != nil {
+		return fmt.Errorf("failed to acknowledge event: %w", err)
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of the payload
+func sign(secret []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verify(secret []byte, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func wait(ctx context.Context, delay time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+}
@@ -25,8 +25,9 @@ type Card struct {
 	Characteristics []string        `json:"characteristics" db:"characteristics"` // -- 特徵標籤
 	EffectText      string          `json:"effect_text" db:"effect_text"`
 	TriggerEffect   string          `json:"trigger_effect" db:"trigger_effect"`
-	Keywords        []string        `json:"keywords" db:"keywords"`   // -- 關鍵字 [レイド, 狙い撃ち, ダメージ2]
-	ImageURL        string          `json:"image_url" db:"image_url"` // 稀有度特定圖片 URL
+	Keywords        []string        `json:"keywords" db:"keywords"`     // -- 關鍵字 [レイド, 狙い撃ち, ダメージ2]
+	Abilities       []CardEffect    `json:"abilities,omitempty" db:"-"` // 在遊戲時點觸發的能力，action.trigger 指定時點
+	ImageURL        string          `json:"image_url" db:"image_url"`   // 稀有度特定圖片 URL
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// EnergyColorless 無色能源需求，可以由任何顏色的剩餘能源滿足
+const EnergyColorless = "colorless"
+
+// EnergyShortage 描述一項未滿足的能源需求
+type EnergyShortage struct {
+	Color     string `json:"color"`
+	Required  int    `json:"required"`
+	Available int    `json:"available"`
+}
+
+// ParseEnergy 解析卡片的能源需求或能源產生資料
+// 資料為空或格式錯誤時返回空的映射
+func ParseEnergy(raw json.RawMessage) map[string]int {
+	energy := map[string]int{}
+	if len(raw) == 0 {
+		return energy
+	}
+	if err := json.Unmarshal(raw, &energy); err != nil {
+		return map[string]int{}
+	}
+	return energy
+}
+
+// AvailableEnergy 計算玩家目前可用的能源
+// 根據 Union Arena 規則，能源由場上（能源線與前線）卡片的 EnergyProduce 產生，使用卡片時不會消耗；另加上效果給予的本回合能源
+func (p *Player) AvailableEnergy() map[string]int {
+	energy := map[string]int{}
+	for _, line := range [][]CardInPlay{p.Board.EnergyLine, p.Board.FrontLine} {
+		for _, card := range line {
+			for color, amount := range ParseEnergy(card.Card.EnergyProduce) {
+				energy[color] += amount
+			}
+		}
+	}
+	for color, amount := range p.EnergyBonus {
+		energy[color] += amount
+	}
+	return energy
+}
+
+// CheckEnergyRequirement 檢查可用能源是否滿足能源需求
+// 各顏色的需求必須由該顏色的能源滿足，無色需求由滿足顏色需求後剩餘的任何能源滿足；返回所有未滿足的需求
+func CheckEnergyRequirement(available, cost map[string]int) []EnergyShortage {
+	colors := make([]string, 0, len(cost))
+	for color := range cost {
+		if color != EnergyColorless {
+			colors = append(colors, color)
+		}
+	}
+	sort.Strings(colors)
+
+	total := 0
+	for _, amount := range available {
+		total += amount
+	}
+
+	shortages := []EnergyShortage{}
+	coloredRequired := 0
+	for _, color := range colors {
+		required := cost[color]
+		coloredRequired += required
+		if available[color] < required {
+			shortages = append(shortages, EnergyShortage{Color: color, Required: required, Available: available[color]})
+		}
+	}
+
+	if required := cost[EnergyColorless]; required > 0 {
+		remaining := total - coloredRequired
+		if remaining < 0 {
+			remaining = 0
+		}
+		if remaining < required {
+			shortages = append(shortages, EnergyShortage{Color: EnergyColorless, Required: required, Available: remaining})
+		}
+	}
+
+	return shortages
+}
@@ -58,35 +58,83 @@ type Game struct {
 	ActivePlayer uuid.UUID       `json:"active_player" db:"active_player"`
 	GameState    json.RawMessage `json:"game_state" db:"game_state"`
 	Winner       *uuid.UUID      `json:"winner" db:"winner"`
-	StartedAt    *time.Time      `json:"started_at" db:"started_at"`
-	CompletedAt  *time.Time      `json:"completed_at" db:"completed_at"`
-	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
+	EndReason    string          `json:"end_reason,omitempty" db:"end_reason"` // one of EndReason*, set once the game is completed
+	GameMode     string          `json:"game_mode" db:"game_mode"`
+	// Spectators may watch the game unless the players opted out; they see the state SpectatorDelay actions late
+	AllowSpectators bool       `json:"allow_spectators" db:"allow_spectators"`
+	SpectatorDelay  int        `json:"spectator_delay" db:"spectator_delay"`
+	StartedAt       *time.Time `json:"started_at" db:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // GameState 代表遊戲的當前狀態
 type GameState struct {
-	Turn              int                   `json:"turn"`
-	Phase             Phase                 `json:"phase"`
-	ActivePlayer      uuid.UUID             `json:"active_player"`
-	FirstPlayer       uuid.UUID             `json:"first_player"`       // 先攻玩家ID
-	Players           map[uuid.UUID]*Player `json:"players"`
-	ActionLog         []GameAction          `json:"action_log"`
-	MulliganCompleted map[uuid.UUID]bool    `json:"mulligan_completed"` // 記錄每個玩家是否完成調度
-	LifeAreaSetup     bool                  `json:"life_area_setup"`    // 記錄是否已設置生命區
+	Version           int64                      `json:"version"`       // 狀態版本，每次成功改變狀態後遞增，儲存時用於樂觀鎖比對
+	Seed              int64                      `json:"seed"`          // 遊戲隨機種子，與動作記錄一起可完整重現遊戲
+	ShuffleCount      int                        `json:"shuffle_count"` // 已洗牌次數，每次洗牌以種子與次數建立隨機數產生器
+	IDCount           int                        `json:"id_count"`      // 已產生的遊戲內識別碼數量，識別碼由種子與數量推導，重播時可重現
+	Turn              int                        `json:"turn"`
+	Phase             Phase                      `json:"phase"`
+	ActivePlayer      uuid.UUID                  `json:"active_player"`
+	FirstPlayer       uuid.UUID                  `json:"first_player"` // 先攻玩家ID
+	Players           map[uuid.UUID]*Player      `json:"players"`
+	ActionLog         []GameAction               `json:"action_log"`
+	MulliganCompleted map[uuid.UUID]bool         `json:"mulligan_completed"`         // 記錄每個玩家是否完成調度
+	LifeAreaSetup     bool                       `json:"life_area_setup"`            // 記錄是否已設置生命區
+	PendingAttack     *PendingAttack             `json:"pending_attack,omitempty"`   // 等待防禦方決定是否防禦的攻擊
+	PendingDecisions  []PendingDecision          `json:"pending_decisions"`          // 等待玩家做出的選擇，第一個為當前選擇
+	TimingEvents      []TimingEvent              `json:"timing_events,omitempty"`    // 已發出但尚未收集觸發效果的時點事件
+	PendingTriggers   []PendingTrigger           `json:"pending_triggers,omitempty"` // 等待處理的觸發效果，依處理順序排列
+	EndingTurn        bool                       `json:"ending_turn,omitempty"`      // 回合正在結束，等待選擇或觸發效果處理完成後推進到下一回合
+	SurrenderedBy     *uuid.UUID                 `json:"surrendered_by,omitempty"`   // 投降的玩家，對手獲勝
+	TimeControl       *TimeControl               `json:"time_control,omitempty"`     // 遊戲的時間限制，未設定時不限時
+	Clocks            map[uuid.UUID]*PlayerClock `json:"clocks,omitempty"`           // 每個玩家剩餘的時間
+	ClockPlayer       *uuid.UUID                 `json:"clock_player,omitempty"`     // 目前計時中的玩家，即需要行動的玩家
+	ClockStartedAt    *time.Time                 `json:"clock_started_at,omitempty"` // 計時玩家的時鐘開始走的時間，剩餘時間記錄的是此時的值
+	TimedOut          *uuid.UUID                 `json:"timed_out,omitempty"`        // 超時次數達到上限的玩家，對手獲勝
+	ConnectionLost    *uuid.UUID                 `json:"connection_lost,omitempty"`  // 斷線後未在期限內重新連線的玩家，對手獲勝
+}
+
+// TimeControl 遊戲的時間限制
+// 玩家在自己的回合先使用回合時間，回合時間用完後或回應對手時使用整場遊戲共用的備用時間，兩者都用完即為超時
+type TimeControl struct {
+	TurnSeconds    int `json:"turn_seconds"`    // 每個自己的回合可用的時間
+	ReserveSeconds int `json:"reserve_seconds"` // 整場遊戲的備用時間
+	MaxTimeouts    int `json:"max_timeouts"`    // 超時達到此次數時判負，0表示超時不會判負
+}
+
+// PlayerClock 玩家剩餘的時間
+type PlayerClock struct {
+	TurnRemainingMs    int64 `json:"turn_remaining_ms"`    // 本回合剩餘的回合時間
+	ReserveRemainingMs int64 `json:"reserve_remaining_ms"` // 剩餘的備用時間
+	Timeouts           int   `json:"timeouts"`             // 已超時的次數
+}
+
+// PendingAttack 代表已宣告但尚未解決的攻擊
+// 根據 Union Arena 規則：攻擊宣告後，被攻擊的玩家可選擇前線1張活動角色進行防禦
+type PendingAttack struct {
+	AttackerID      uuid.UUID  `json:"attacker_id"`         // 攻擊角色的卡片ID
+	AttackingPlayer uuid.UUID  `json:"attacking_player"`    // 攻擊方玩家
+	DefendingPlayer uuid.UUID  `json:"defending_player"`    // 防禦方玩家（擁有防禦窗口）
+	TargetType      string     `json:"target_type"`         // "player" or "character"
+	TargetID        *uuid.UUID `json:"target_id,omitempty"` // 攻擊角色時的目標卡片ID
 }
 
 // Player 代表遊戲中的玩家
 // 根據 Union Arena 規則，每個玩家都有自己的區域
 type Player struct {
 	ID            uuid.UUID      `json:"id"`
-	AP            int            `json:"ap"`             // 當前可用AP
-	MaxAP         int            `json:"max_ap"`         // 本回合最大AP
-	Energy        map[string]int `json:"energy"`         // 各種顏色能源數量
-	Hand          []Card         `json:"hand"`           // 手牌
-	Deck          []Card         `json:"deck"`           // 卡組區
-	Board         Board          `json:"board"`          // 玩家的場地區域
-	ExtraDrawUsed bool           `json:"extra_draw_used"` // 本回合是否已使用額外抽卡
+	AP            int            `json:"ap"`                     // 當前可用AP
+	MaxAP         int            `json:"max_ap"`                 // 本回合最大AP
+	Energy        map[string]int `json:"energy"`                 // 場上卡片目前產生的各色能源，由引擎根據場上卡片計算
+	EnergyBonus   map[string]int `json:"energy_bonus,omitempty"` // 效果給予的本回合能源
+	Hand          []Card         `json:"hand"`                   // 手牌
+	Deck          []Card         `json:"deck"`                   // 卡組區
+	DeckCount     int            `json:"deck_count,omitempty"`   // 卡組張數，只在隱藏卡組內容的玩家視角中設定
+	Board         Board          `json:"board"`                  // 玩家的場地區域
+	ExtraDrawUsed bool           `json:"extra_draw_used"`        // 本回合是否已使用額外抽卡
 }
 
 // Board 代表每個玩家的遊戲場地區域
@@ -103,12 +151,45 @@ type Board struct {
 	HiddenArea  []Card       `json:"hidden_area"`  // 隱藏區域：暫時放置卡片的隱藏區域
 }
 
+// PendingDecision 代表引擎等待玩家做出的選擇
+// 在選擇被解決前，引擎只接受該玩家的 RESOLVE_DECISION 動作（投降除外）
+type PendingDecision struct {
+	ID             uuid.UUID         `json:"id"`
+	Type           string            `json:"type"`                     // 選擇類型，決定選擇解決後的處理方式
+	PlayerID       uuid.UUID         `json:"player_id"`                // 需要做出選擇的玩家
+	Prompt         string            `json:"prompt"`                   // 顯示給玩家的提示
+	Options        []DecisionOption  `json:"options"`                  // 可選擇的選項
+	MinChoices     int               `json:"min_choices"`              // 最少需要選擇的數量
+	MaxChoices     int               `json:"max_choices"`              // 最多可以選擇的數量
+	DefaultChoices []string          `json:"default_choices"`          // 超過期限時採用的選擇
+	Deadline       time.Time         `json:"deadline"`                 // 選擇期限
+	SourceCardID   *uuid.UUID        `json:"source_card_id,omitempty"` // 引發選擇的卡片
+	Context        map[string]string `json:"context,omitempty"`        // 選擇解決後繼續處理所需的資料
+}
+
+// DecisionOption 代表待決選擇中的一個選項
+type DecisionOption struct {
+	ID     string     `json:"id"`
+	Label  string     `json:"label"`
+	CardID *uuid.UUID `json:"card_id,omitempty"`
+}
+
+const (
+	DecisionTypeLifeTrigger    = "LIFE_TRIGGER"    // 生命區翻開的卡片是否發動觸發效果
+	DecisionTypeDiscard        = "DISCARD"         // 選擇手牌放置到移除區（手牌上限）
+	DecisionTypeTarget         = "TARGET"          // 選擇效果的對象
+	DecisionTypeOptionalEffect = "OPTIONAL_EFFECT" // 是否發動可選效果
+	DecisionTypeTriggerOrder   = "TRIGGER_ORDER"   // 決定同時觸發的效果的處理順序
+)
+
 type CardInPlay struct {
-	Card      Card           `json:"card"`
-	Position  Position       `json:"position"`
-	Status    CardStatus     `json:"status"`
-	Modifiers []CardModifier `json:"modifiers"`
-	Owner     uuid.UUID      `json:"owner"`
+	Card       Card           `json:"card"`
+	Position   Position       `json:"position"`
+	Status     CardStatus     `json:"status"`
+	Modifiers  []CardModifier `json:"modifiers"`
+	Owner      uuid.UUID      `json:"owner"`
+	PlayedTurn int            `json:"played_turn"`     // 登場的回合，登場當回合不可移動
+	Stack      []Card         `json:"stack,omitempty"` // 突襲時疊在下方的卡片，由下而上排列
 }
 
 // Position 表示卡片在場上的具體位置
@@ -127,47 +208,72 @@ type CardStatus struct {
 	CanAct      bool `json:"can_act"`      // 是否可以行動（綜合判定）
 }
 
+// CardModifier 角色身上由效果產生的修正
+// 修正器在持續範圍結束時由引擎移除，角色離場時一併消失
 type CardModifier struct {
-	Type      string      `json:"type"`
-	Value     interface{} `json:"value"`
-	Duration  int         `json:"duration"`
-	Source    uuid.UUID   `json:"source"`
-	AppliedAt int         `json:"applied_at"`
+	Type       string      `json:"type"`       // 修正類型，見 ModifierType 常數
+	Value      interface{} `json:"value"`      // BP修正的增減值，其他類型不使用
+	Scope      string      `json:"scope"`      // 持續範圍，見 ModifierScope 常數
+	Source     uuid.UUID   `json:"source"`     // 產生修正的卡片
+	Controller uuid.UUID   `json:"controller"` // 產生修正的玩家，用於判斷「對手的下個回合」
+	AppliedAt  int         `json:"applied_at"` // 產生修正的回合
 }
 
+const (
+	ModifierTypeBP           = "bp_boost"      // BP增減
+	ModifierTypeCannotAttack = "cannot_attack" // 不能攻擊
+	ModifierTypeCannotBlock  = "cannot_block"  // 不能防禦
+	ModifierTypeNoActivate   = "no_activate"   // 下一次起始階段不轉為活動狀態
+)
+
+const (
+	ModifierScopeThisTurn           = "this_turn"             // 在這個回合中，於結束階段失效
+	ModifierScopeOpponentNextTurn   = "opponent_next_turn"    // 直到對手的下個回合結束
+	ModifierScopeWhileSourceOnBoard = "while_source_on_board" // 產生修正的卡片在場上期間
+	ModifierScopeWhileOnBoard       = "while_on_board"        // 直到角色離場
+)
+
 type GameAction struct {
-	ID         uuid.UUID       `json:"id"`
-	GameID     uuid.UUID       `json:"game_id"`
-	PlayerID   uuid.UUID       `json:"player_id"`
-	ActionType string          `json:"action_type"`
-	ActionData json.RawMessage `json:"action_data"`
-	Turn       int             `json:"turn"`
-	Phase      Phase           `json:"phase"`
-	Timestamp  time.Time       `json:"timestamp"`
-	IsValid    bool            `json:"is_valid"`
-	ErrorMsg   string          `json:"error_msg,omitempty"`
+	ID             uuid.UUID       `json:"id"`
+	GameID         uuid.UUID       `json:"game_id"`
+	PlayerID       uuid.UUID       `json:"player_id"`
+	ActionType     string          `json:"action_type"`
+	ActionData     json.RawMessage `json:"action_data"`
+	Turn           int             `json:"turn"`
+	Phase          Phase           `json:"phase"`
+	Timestamp      time.Time       `json:"timestamp"`
+	IsValid        bool            `json:"is_valid"`
+	ErrorMsg       string          `json:"error_msg,omitempty"`
+	SequenceNumber int64           `json:"sequence_number"` // 動作序號，等於動作完成後的遊戲狀態版本，同一場遊戲內連續且唯一
 }
 
 type ActionData struct {
-	CardID     *uuid.UUID             `json:"card_id,omitempty"`
-	TargetID   *uuid.UUID             `json:"target_id,omitempty"`
-	TargetType string                 `json:"target_type,omitempty"` // "player" or "character"
-	Position   *Position              `json:"position,omitempty"`
-	Value      interface{}            `json:"value,omitempty"`
-	Additional map[string]interface{} `json:"additional,omitempty"`
+	CardID       *uuid.UUID             `json:"card_id,omitempty"`
+	TargetID     *uuid.UUID             `json:"target_id,omitempty"`
+	TargetType   string                 `json:"target_type,omitempty"` // "player" or "character"
+	Position     *Position              `json:"position,omitempty"`
+	Value        interface{}            `json:"value,omitempty"`
+	DecisionID   *uuid.UUID             `json:"decision_id,omitempty"`    // RESOLVE_DECISION：要回應的選擇ID
+	Choices      []string               `json:"choices,omitempty"`        // RESOLVE_DECISION：選擇的選項ID
+	RaidTargetID *uuid.UUID             `json:"raid_target_id,omitempty"` // PLAY_CARD：以突襲方式疊加的場上角色
+	Additional   map[string]interface{} `json:"additional,omitempty"`
 }
 
 const (
-	ActionTypeDrawCard       = "DRAW_CARD"
-	ActionTypeExtraDraw      = "EXTRA_DRAW"      // 額外抽卡（支付1AP）
-	ActionTypePlayCard       = "PLAY_CARD"
-	ActionTypeAttack         = "ATTACK"
-	ActionTypeBlock          = "BLOCK"
-	ActionTypeActivateEffect = "ACTIVATE_EFFECT"
-	ActionTypeMoveCharacter  = "MOVE_CHARACTER"
-	ActionTypeEndPhase       = "END_PHASE"
-	ActionTypeEndTurn        = "END_TURN"
-	ActionTypeSurrender      = "SURRENDER"
+	ActionTypeDrawCard        = "DRAW_CARD"
+	ActionTypeExtraDraw       = "EXTRA_DRAW"      // 額外抽卡（支付1AP）
+	ActionTypePlayCard        = "PLAY_CARD"
+	ActionTypeAttack          = "ATTACK"
+	ActionTypeBlock           = "BLOCK"
+	ActionTypeActivateEffect  = "ACTIVATE_EFFECT"
+	ActionTypeMoveCharacter   = "MOVE_CHARACTER"
+	ActionTypeEndPhase        = "END_PHASE"
+	ActionTypeEndTurn         = "END_TURN"
+	ActionTypeSurrender       = "SURRENDER"
+	ActionTypeResolveDecision = "RESOLVE_DECISION" // 回應引擎的待決選擇
+	ActionTypeMulligan        = "MULLIGAN"         // 調度手牌，只出現在動作記錄中，不能經由動作API送出
+	ActionTypeTimeout         = "TIMEOUT"          // 計時玩家的時間用完，由伺服器代為行動
+	ActionTypeConnectionLost  = "CONNECTION_LOST"  // 玩家斷線超過重新連線期限，只由伺服器送出
 )
 
 type GameResult struct {
@@ -179,9 +285,20 @@ type GameResult struct {
 	GameDuration int        `json:"game_duration" db:"game_duration"`
 	TotalTurns   int        `json:"total_turns" db:"total_turns"`
 	EndReason    string     `json:"end_reason" db:"end_reason"`
+	GameMode     string     `json:"game_mode" db:"game_mode"`
 	CompletedAt  time.Time  `json:"completed_at" db:"completed_at"`
 }
 
+// 遊戲結束原因，對應 game_results.end_reason
+const (
+	EndReasonNormalWin      = "NORMAL_WIN"
+	EndReasonSurrender      = "SURRENDER"
+	EndReasonTimeout        = "TIMEOUT"
+	EndReasonDeckOut        = "DECK_OUT"
+	EndReasonConnectionLost = "CONNECTION_LOST"
+	EndReasonDraw           = "DRAW"
+)
+
 type MatchmakingRequest struct {
 	UserID      uuid.UUID `json:"user_id"`
 	Mode        string    `json:"mode"`
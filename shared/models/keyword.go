@@ -0,0 +1,129 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 關鍵字名稱
+const (
+	KeywordRaid         = "レイド"
+	KeywordSnipe        = "狙い撃ち"
+	KeywordDamage       = "ダメージ"
+	KeywordImpact       = "インパクト"
+	KeywordImpactNegate = "インパクト無効"
+	KeywordStep         = "ステップ"
+)
+
+// KeywordDefinition 關鍵字目錄中的定義
+type KeywordDefinition struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	HasValue     bool   `json:"has_value"`     // 是否帶有數值參數，如 ダメージ2
+	DefaultValue int    `json:"default_value"` // 未標示數值時使用的數值，為0時必須標示數值
+}
+
+// Keyword 從卡片關鍵字文字解析出的關鍵字
+type Keyword struct {
+	Name  string `json:"name"`
+	Value int    `json:"value,omitempty"`
+	Raw   string `json:"raw"`
+}
+
+var keywordCatalogue = []KeywordDefinition{
+	{
+		Name:        KeywordRaid,
+		Description: "突襲：可疊加在場上同名或同特徵的未持有レイド的角色上登場，繼承其位置與活動狀態",
+	},
+	{
+		Name:        KeywordSnipe,
+		Description: "狙い撃ち：攻擊時可以指定對手前線的1張角色為攻擊對象，對手無法防禦",
+	},
+	{
+		Name:        KeywordDamage,
+		Description: "ダメージ：攻擊未被防禦時，對玩家造成的傷害改為N點",
+		HasValue:    true,
+	},
+	{
+		Name:         KeywordImpact,
+		Description:  "インパクト：攻擊使防禦或被攻擊的角色退場時，對對手玩家造成N點傷害",
+		HasValue:     true,
+		DefaultValue: 1,
+	},
+	{
+		Name:        KeywordImpactNegate,
+		Description: "インパクト無効：此角色因戰鬥退場時，攻擊方的インパクト不造成傷害",
+	},
+	{
+		Name:        KeywordStep,
+		Description: "ステップ：移動階段時可以從前線移回能源線",
+	},
+}
+
+// KeywordCatalogue 返回所有已定義的關鍵字
+func KeywordCatalogue() []KeywordDefinition {
+	catalogue := make([]KeywordDefinition, len(keywordCatalogue))
+	copy(catalogue, keywordCatalogue)
+	return catalogue
+}
+
+// LookupKeyword 根據名稱查詢關鍵字定義
+func LookupKeyword(name string) (KeywordDefinition, bool) {
+	for _, definition := range keywordCatalogue {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return KeywordDefinition{}, false
+}
+
+// ParseKeyword 解析卡片上的關鍵字文字
+// 完全符合名稱的關鍵字優先（如 インパクト無効），其次為名稱加數值參數（如 ダメージ2、インパクト１）
+// 數值尚未定義的關鍵字不會被解析，如 ダメージ●，以及沒有預設數值卻未標示數值的 ダメージ
+func ParseKeyword(raw string) (Keyword, bool) {
+	text := strings.TrimSpace(raw)
+
+	if definition, exists := LookupKeyword(text); exists && (!definition.HasValue || definition.DefaultValue > 0) {
+		return Keyword{Name: definition.Name, Value: definition.DefaultValue, Raw: raw}, true
+	}
+
+	for _, definition := range keywordCatalogue {
+		if !definition.HasValue || !strings.HasPrefix(text, definition.Name) {
+			continue
+		}
+		if value, ok := parseKeywordValue(strings.TrimPrefix(text, definition.Name)); ok {
+			return Keyword{Name: definition.Name, Value: value, Raw: raw}, true
+		}
+	}
+
+	return Keyword{}, false
+}
+
+// ParseKeywords 解析卡片的所有關鍵字，忽略目錄中沒有定義的關鍵字
+func ParseKeywords(raws []string) []Keyword {
+	keywords := []Keyword{}
+	for _, raw := range raws {
+		if keyword, ok := ParseKeyword(raw); ok {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// parseKeywordValue 解析關鍵字的數值參數
+// 支援半形與全形數字；「●」等非數字的參數沒有定義數值，不會被解析
+func parseKeywordValue(param string) (int, bool) {
+	param = strings.TrimSpace(param)
+	normalized := strings.Map(func(r rune) rune {
+		if r >= '０' && r <= '９' {
+			return r - '０' + '0'
+		}
+		return r
+	}, param)
+
+	value, err := strconv.Atoi(normalized)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,56 @@
+package models
+
+import "github.com/google/uuid"
+
+// 遊戲時點，卡片能力以 action.trigger 指定要回應的時點
+const (
+	TimingOnPlay       = "on_play"        // 卡片登場時
+	TimingOnAttack     = "on_attack"      // 角色宣告攻擊時
+	TimingOnBlock      = "on_block"       // 角色宣告防禦時
+	TimingOnRetire     = "on_retire"      // 角色退場時
+	TimingOnLifeDamage = "on_life_damage" // 玩家受到傷害，生命區卡片被翻開時
+	TimingStartOfTurn  = "start_of_turn"  // 回合開始時
+	TimingEndOfTurn    = "end_of_turn"    // 回合結束時
+	TimingOnDraw       = "on_draw"        // 玩家在遊戲中抽牌時
+)
+
+// 卡片能力回應的事件範圍，以 action.watch 指定
+const (
+	WatchSelf       = "self"       // 只回應與自身有關的事件
+	WatchController = "controller" // 回應自己（卡片控制者）的事件
+	WatchOpponent   = "opponent"   // 回應對手的事件
+	WatchAny        = "any"        // 回應雙方的事件
+)
+
+// TimingPoints 返回所有遊戲時點
+func TimingPoints() []string {
+	return []string{
+		TimingOnPlay,
+		TimingOnAttack,
+		TimingOnBlock,
+		TimingOnRetire,
+		TimingOnLifeDamage,
+		TimingStartOfTurn,
+		TimingEndOfTurn,
+		TimingOnDraw,
+	}
+}
+
+// TimingEvent 引擎在遊戲時點發出的事件
+// 同一個動作中發出的事件視為同時發生，其觸發的效果一起排序
+type TimingEvent struct {
+	Timing   string                 `json:"timing"`
+	PlayerID uuid.UUID              `json:"player_id"`      // 事件相關的玩家（登場、攻擊、抽牌的玩家，受到傷害的玩家等）
+	Card     *Card                  `json:"card,omitempty"` // 事件相關的卡片，退場時為退場前的卡片資料
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// PendingTrigger 已觸發但尚未處理的卡片能力
+type PendingTrigger struct {
+	ID         uuid.UUID  `json:"id"`
+	Timing     string     `json:"timing"`
+	PlayerID   uuid.UUID  `json:"player_id"`   // 控制觸發效果的玩家
+	SourceCard Card       `json:"source_card"` // 持有能力的卡片
+	Effect     CardEffect `json:"effect"`      // 已代入對象的效果
+	Ordered    bool       `json:"ordered"`     // 控制者是否已決定同時觸發的效果的處理順序
+}
@@ -0,0 +1,127 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"ua/shared/logger"
+	"ua/shared/redis"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Who an envelope is delivered to
+const (
+	TargetGame       = "game"
+	TargetSpectators = "spectators"
+	TargetUser       = "user"
+)
+
+// publishTimeout bounds how long publishing one message to Redis may take
+const publishTimeout = 5 * time.Second
+
+// Envelope is a message on its way to the clients of a game room, the spectators of a game or a user
+type Envelope struct {
+	Target  string
+	ID      uuid.UUID
+	Message []byte
+}
+
+// Backend fans hub messages out to the hubs that hold the receiving clients.
+// Every envelope published must be delivered to every subscribed hub, the publishing one included;
+// each hub then sends it to whichever of its own clients it is for.
+type Backend interface {
+	Publish(envelope Envelope)
+	Subscribe(deliver func(Envelope))
+}
+
+// localBackend delivers envelopes straight to the hub, for a single instance
+type localBackend struct {
+	deliver func(Envelope)
+}
+
+func NewLocalBackend() Backend {
+	return &localBackend{}
+}
+
+func (b *localBackend) Publish(envelope Envelope) {
+	if b.deliver != nil {
+		b.deliver(envelope)
+	}
+}
+
+func (b *localBackend) Subscribe(deliver func(Envelope)) {
+	b.deliver = deliver
+}
+
+// redisBackend fans envelopes out to every instance over a Redis pub/sub channel.
+// Messages published while an instance is reconnecting to Redis are lost to it; game clients recover with a resync.
+type redisBackend struct {
+	client  *redis.Client
+	channel string
+}
+
+func NewRedisBackend(client *redis.Client, channel string) Backend {
+	return &redisBackend{
+		client:  client,
+		channel: channel,
+	}
+}
+
+func (b *redisBackend) Publish(envelope Envelope) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := b.client.Publish(ctx, b.channel, encodeEnvelope(envelope)).Err(); err != nil {
+		logger.Error("Failed to publish WebSocket message",
+			zap.String("target", envelope.Target),
+			zap.String("id", envelope.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// Subscribe delivers envelopes from the channel on its own goroutine until the Redis client is closed
+func (b *redisBackend) Subscribe(deliver func(Envelope)) {
+	pubsub := b.client.Subscribe(context.Background(), b.channel)
+
+	go func() {
+		defer pubsub.Close()
+		for message := range pubsub.Channel() {
+			envelope, err := decodeEnvelope([]byte(message.Payload))
+			if err != nil {
+				logger.Error("Invalid WebSocket message from Redis", zap.Error(err))
+				continue
+			}
+			deliver(envelope)
+		}
+	}()
+
+	logger.Info("Subscribed to WebSocket messages", zap.String("channel", b.channel))
+}
+
+// encodeEnvelope writes the target and ID on a header line in front of the message
+func encodeEnvelope(envelope Envelope) []byte {
+	header := envelope.Target + " " + envelope.ID.String() + "\n"
+	data := make([]byte, 0, len(header)+len(envelope.Message))
+	data = append(data, header...)
+	return append(data, envelope.Message...)
+}
+
+func decodeEnvelope(data []byte) (Envelope, error) {
+	header, message, found := bytes.Cut(data, []byte("\n"))
+	if !found {
+		return Envelope{}, fmt.Errorf("missing envelope header")
+	}
+	target, id, found := bytes.Cut(header, []byte(" "))
+	if !found {
+		return Envelope{}, fmt.Errorf("invalid envelope header")
+	}
+	parsedID, err := uuid.ParseBytes(id)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("invalid envelope ID: %w", err)
+	}
+	return Envelope{Target: string(target), ID: parsedID, Message: message}, nil
+}
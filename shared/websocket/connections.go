@@ -0,0 +1,215 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"ua/shared/logger"
+	"ua/shared/redis"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// connectionTTL is how long a user's shared connection count outlives the last heartbeat of their connections,
+// so counts left behind by an instance that crashed expire
+const connectionTTL = 5 * time.Minute
+
+// countAttempts is how many times a shared connection count update is tried before it is given up
+const countAttempts = 3
+
+// countRetryDelay is the wait between attempts to update a shared connection count
+const countRetryDelay = time.Second
+
+// Connections counts the player connections each user has open; spectator connections are not counted.
+// The hub calls Add and Remove off its Run loop, in the order each user's clients register and unregister,
+// so they may block.
+type Connections interface {
+	// Add records an opened connection and reports whether it is the user's only one
+	Add(userID uuid.UUID) bool
+	// Remove records a closed connection and reports whether it was the user's last
+	Remove(userID uuid.UUID) bool
+	// Refresh is called whenever a connection of the user answers a ping
+	Refresh(userID uuid.UUID)
+	IsConnected(userID uuid.UUID) bool
+}
+
+// localConnections counts the connections to this instance only
+type localConnections struct {
+	mu     sync.Mutex
+	counts map[uuid.UUID]int
+}
+
+func NewLocalConnections() Connections {
+	return &localConnections{counts: make(map[uuid.UUID]int)}
+}
+
+func (c *localConnections) Add(userID uuid.UUID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[userID]++
+	return c.counts[userID] == 1
+}
+
+func (c *localConnections) Remove(userID uuid.UUID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[userID]--
+	if c.counts[userID] > 0 {
+		return false
+	}
+	delete(c.counts, userID)
+	return true
+}
+
+func (c *localConnections) Refresh(userID uuid.UUID) {}
+
+func (c *localConnections) IsConnected(userID uuid.UUID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[userID] > 0
+}
+
+// redisConnections keeps one count per user in Redis, shared by every instance, so a player who reconnects
+// through another instance is seen as connected everywhere
+type redisConnections struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisConnections(client *redis.Client, prefix string) Connections {
+	return &redisConnections{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (c *redisConnections) key(userID uuid.UUID) string {
+	return c.prefix + ":" + userID.String()
+}
+
+// update runs a change to the user's count, retrying when Redis fails, and returns the new count
+func (c *redisConnections) update(userID uuid.UUID, change func(ctx context.Context, key string) (int64, error)) (int64, error) {
+	var err error
+	for attempt := 1; attempt <= countAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(countRetryDelay)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		var count int64
+		count, err = change(ctx, c.key(userID))
+		cancel()
+		if err == nil {
+			return count, nil
+		}
+	}
+	return 0, err
+}
+
+func (c *redisConnections) Add(userID uuid.UUID) bool {
+	count, err := c.update(userID, func(ctx context.Context, key string) (int64, error) {
+		count, err := c.client.Incr(ctx, key).Result()
+		if err != nil {
+			return 0, err
+		}
+		c.client.Expire(ctx, key, connectionTTL)
+		return count, nil
+	})
+	if err != nil {
+		logger.Error("Failed to count WebSocket connection",
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+		// Treated as a new arrival, which at worst resyncs the player
+		return true
+	}
+	return count == 1
+}
+
+func (c *redisConnections) Remove(userID uuid.UUID) bool {
+	count, err := c.update(userID, func(ctx context.Context, key string) (int64, error) {
+		count, err := c.client.Decr(ctx, key).Result()
+		if err != nil {
+			return 0, err
+		}
+		if count <= 0 {
+			c.client.Del(ctx, key)
+		}
+		return count, nil
+	})
+	if err != nil {
+		logger.Error("Failed to count closed WebSocket connection",
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+		// Not treated as a disconnect, so an outage never starts reconnect windows; the count expires
+		// connectionTTL after the user's last heartbeat, and turn timers still apply
+		return false
+	}
+	return count <= 0
+}
+
+func (c *redisConnections) Refresh(userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	c.client.Expire(ctx, c.key(userID), connectionTTL)
+}
+
+func (c *redisConnections) IsConnected(userID uuid.UUID) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	count, err := c.client.Get(ctx, c.key(userID)).Int64()
+	if errors.Is(err, goredis.Nil) {
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to check WebSocket connections",
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+		// Assumed connected, so games are not ended for lost connections while Redis is unreachable
+		return true
+	}
+	return count > 0
+}
+
+// userQueue runs queued work for each user in order, one user's work at a time, on a goroutine per user with work
+type userQueue struct {
+	mu      sync.Mutex
+	pending map[uuid.UUID][]func()
+}
+
+func newUserQueue() *userQueue {
+	return &userQueue{pending: make(map[uuid.UUID][]func())}
+}
+
+// push queues work for the user, starting the user's goroutine when none is running
+func (q *userQueue) push(userID uuid.UUID, work func()) {
+	q.mu.Lock()
+	queued, running := q.pending[userID]
+	q.pending[userID] = append(queued, work)
+	q.mu.Unlock()
+
+	if !running {
+		go q.drain(userID)
+	}
+}
+
+// drain runs the user's work until none is left
+func (q *userQueue) drain(userID uuid.UUID) {
+	for {
+		q.mu.Lock()
+		queued := q.pending[userID]
+		if len(queued) == 0 {
+			delete(q.pending, userID)
+			q.mu.Unlock()
+			return
+		}
+		q.pending[userID] = queued[1:]
+		q.mu.Unlock()
+
+		queued[0]()
+	}
+}
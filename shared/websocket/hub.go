@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -23,21 +24,36 @@ var upgrader = websocket.Upgrader{
 }
 
 type Hub struct {
-	clients    map[uuid.UUID]*Client
-	register   chan *Client
-	unregister chan *Client
-	broadcast  chan []byte
-	mutex      sync.RWMutex
-	gameRooms  map[uuid.UUID]*GameRoom
+	clients     map[uuid.UUID]*Client
+	register    chan *Client
+	unregister  chan *Client
+	broadcast   chan []byte
+	mutex       sync.RWMutex
+	gameRooms   map[uuid.UUID]*GameRoom
+	presence    PresenceHandler
+	requests    RequestHandler
+	backend     Backend
+	connections Connections
+	// Counts each user's connections and tells the presence handler, off the Run loop
+	presenceQueue *userQueue
+}
+
+// PresenceHandler is told when a user's first player connection opens and when their last one closes.
+// Spectator connections do not count. The methods run off the hub's Run loop, one at a time for each user,
+// so they may block.
+type PresenceHandler interface {
+	UserConnected(userID uuid.UUID)
+	UserDisconnected(userID uuid.UUID)
 }
 
 type Client struct {
-	ID     uuid.UUID
-	UserID uuid.UUID
-	Conn   *websocket.Conn
-	Send   chan []byte
-	Hub    *Hub
-	GameID *uuid.UUID
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Conn      *websocket.Conn
+	Send      chan []byte
+	Hub       *Hub
+	GameID    *uuid.UUID
+	Spectator bool // watching GameID rather than playing in it
 }
 
 type GameRoom struct {
@@ -47,35 +63,85 @@ type GameRoom struct {
 }
 
 type Message struct {
-	Type    string      `json:"type"`
-	Payload interface{} `json:"payload"`
-	From    *uuid.UUID  `json:"from,omitempty"`
-	To      *uuid.UUID  `json:"to,omitempty"`
-	GameID  *uuid.UUID  `json:"game_id,omitempty"`
+	Type      string      `json:"type"`
+	Payload   interface{} `json:"payload"`
+	From      *uuid.UUID  `json:"from,omitempty"`
+	To        *uuid.UUID  `json:"to,omitempty"`
+	GameID    *uuid.UUID  `json:"game_id,omitempty"`
+	RequestID string      `json:"request_id,omitempty"` // set on replies to a client request
 }
 
 func NewHub() *Hub {
-	return &Hub{
-		clients:    make(map[uuid.UUID]*Client),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan []byte),
-		gameRooms:  make(map[uuid.UUID]*GameRoom),
+	hub := &Hub{
+		clients:       make(map[uuid.UUID]*Client),
+		register:      make(chan *Client),
+		unregister:    make(chan *Client),
+		broadcast:     make(chan []byte),
+		gameRooms:     make(map[uuid.UUID]*GameRoom),
+		presenceQueue: newUserQueue(),
+	}
+	hub.SetBackend(NewLocalBackend())
+	hub.SetConnections(NewLocalConnections())
+	return hub
+}
+
+// SetBackend sets how BroadcastToGame, BroadcastToSpectators and SendToUser reach clients, which may be connected
+// to other instances. The hub starts with a local backend that only reaches its own clients. Call it before Run.
+func (h *Hub) SetBackend(backend Backend) {
+	h.backend = backend
+	backend.Subscribe(h.deliver)
+}
+
+// deliver sends an envelope from the backend to the clients of this hub it is for
+func (h *Hub) deliver(envelope Envelope) {
+	switch envelope.Target {
+	case TargetGame:
+		h.broadcastToGame(envelope.ID, envelope.Message)
+	case TargetSpectators:
+		h.broadcastToSpectators(envelope.ID, envelope.Message)
+	case TargetUser:
+		h.sendToUser(envelope.ID, envelope.Message)
+	default:
+		logger.Error("Unknown WebSocket message target", zap.String("target", envelope.Target))
 	}
 }
 
+// SetConnections sets where the player connections of each user are counted, which decides when the presence handler
+// is told about them. The hub starts counting only its own clients; use shared connections together with a shared
+// backend. Call it before Run.
+func (h *Hub) SetConnections(connections Connections) {
+	h.connections = connections
+}
+
+// SetPresenceHandler sets the handler told about users connecting and disconnecting. Call it before Run.
+func (h *Hub) SetPresenceHandler(handler PresenceHandler) {
+	h.presence = handler
+}
+
 func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.register:
 			h.mutex.Lock()
 			h.clients[client.ID] = client
+			if client.GameID != nil {
+				h.addClientToGameRoom(*client.GameID, client)
+			}
 			h.mutex.Unlock()
 			logger.Info("Client connected", zap.String("client_id", client.ID.String()))
 
+			if !client.Spectator {
+				h.presenceQueue.push(client.UserID, func() {
+					if h.connections.Add(client.UserID) && h.presence != nil {
+						h.presence.UserConnected(client.UserID)
+					}
+				})
+			}
+
 		case client := <-h.unregister:
 			h.mutex.Lock()
-			if _, ok := h.clients[client.ID]; ok {
+			_, registered := h.clients[client.ID]
+			if registered {
 				delete(h.clients, client.ID)
 				close(client.Send)
 				if client.GameID != nil {
@@ -85,14 +151,21 @@ func (h *Hub) Run() {
 			h.mutex.Unlock()
 			logger.Info("Client disconnected", zap.String("client_id", client.ID.String()))
 
+			if registered && !client.Spectator {
+				h.presenceQueue.push(client.UserID, func() {
+					if h.connections.Remove(client.UserID) && h.presence != nil {
+						h.presence.UserDisconnected(client.UserID)
+					}
+				})
+			}
+
 		case message := <-h.broadcast:
 			h.mutex.RLock()
 			for _, client := range h.clients {
 				select {
 				case client.Send <- message:
 				default:
-					delete(h.clients, client.ID)
-					close(client.Send)
+					logSkipped(client)
 				}
 			}
 			h.mutex.RUnlock()
@@ -127,6 +200,43 @@ func (h *Hub) HandleWebSocket(c *gin.Context) {
 	go client.readPump()
 }
 
+// HandleSpectator upgrades the request to a WebSocket that watches a game.
+// The client joins the game room as a spectator and receives snapshot before any broadcast.
+func (h *Hub) HandleSpectator(c *gin.Context, gameID uuid.UUID, snapshot []byte) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Error("Failed to upgrade connection", zap.Error(err))
+		return
+	}
+
+	client := &Client{
+		ID:        uuid.New(),
+		UserID:    userID.(uuid.UUID),
+		Conn:      conn,
+		Send:      make(chan []byte, 256),
+		Hub:       h,
+		GameID:    &gameID,
+		Spectator: true,
+	}
+	client.Send <- snapshot
+
+	h.register <- client
+
+	go client.writePump()
+	go client.readPump()
+
+	logger.Info("Spectator joined game",
+		zap.String("game_id", gameID.String()),
+		zap.String("user_id", client.UserID.String()))
+}
+
+// JoinGameRoom moves a client into a game room, leaving the room it was in
 func (h *Hub) JoinGameRoom(gameID uuid.UUID, clientID uuid.UUID) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
@@ -136,6 +246,40 @@ func (h *Hub) JoinGameRoom(gameID uuid.UUID, clientID uuid.UUID) {
 		return
 	}
 
+	if client.GameID != nil {
+		h.removeClientFromGameRoom(*client.GameID, client.ID)
+	}
+	h.addClientToGameRoom(gameID, client)
+}
+
+// LeaveGameRoom takes a client out of the game room it is in
+func (h *Hub) LeaveGameRoom(clientID uuid.UUID) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	client, exists := h.clients[clientID]
+	if !exists || client.GameID == nil {
+		return
+	}
+
+	h.removeClientFromGameRoom(*client.GameID, client.ID)
+	client.GameID = nil
+}
+
+// clientGame returns the game room a client is in
+func (h *Hub) clientGame(clientID uuid.UUID) *uuid.UUID {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	client, exists := h.clients[clientID]
+	if !exists || client.GameID == nil {
+		return nil
+	}
+	gameID := *client.GameID
+	return &gameID
+}
+
+func (h *Hub) addClientToGameRoom(gameID uuid.UUID, client *Client) {
 	if _, exists := h.gameRooms[gameID]; !exists {
 		h.gameRooms[gameID] = &GameRoom{
 			ID:      gameID,
@@ -145,7 +289,7 @@ func (h *Hub) JoinGameRoom(gameID uuid.UUID, clientID uuid.UUID) {
 
 	room := h.gameRooms[gameID]
 	room.mutex.Lock()
-	room.Clients[clientID] = client
+	room.Clients[client.ID] = client
 	room.mutex.Unlock()
 
 	client.GameID = &gameID
@@ -168,6 +312,10 @@ func (h *Hub) removeClientFromGameRoom(gameID uuid.UUID, clientID uuid.UUID) {
 }
 
 func (h *Hub) BroadcastToGame(gameID uuid.UUID, message []byte) {
+	h.backend.Publish(Envelope{Target: TargetGame, ID: gameID, Message: message})
+}
+
+func (h *Hub) broadcastToGame(gameID uuid.UUID, message []byte) {
 	h.mutex.RLock()
 	room, exists := h.gameRooms[gameID]
 	h.mutex.RUnlock()
@@ -183,25 +331,88 @@ func (h *Hub) BroadcastToGame(gameID uuid.UUID, message []byte) {
 		select {
 		case client.Send <- message:
 		default:
-			close(client.Send)
-			delete(room.Clients, client.ID)
+			logSkipped(client)
+		}
+	}
+}
+
+// BroadcastToSpectators sends a message to the spectators of a game only.
+// A spectator whose send buffer is full skips the message rather than being disconnected.
+func (h *Hub) BroadcastToSpectators(gameID uuid.UUID, message []byte) {
+	h.backend.Publish(Envelope{Target: TargetSpectators, ID: gameID, Message: message})
+}
+
+func (h *Hub) broadcastToSpectators(gameID uuid.UUID, message []byte) {
+	h.mutex.RLock()
+	room, exists := h.gameRooms[gameID]
+	h.mutex.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+
+	for _, client := range room.Clients {
+		if !client.Spectator {
+			continue
+		}
+		select {
+		case client.Send <- message:
+		default:
+			logger.Debug("Spectator send buffer full, skipping message",
+				zap.String("game_id", gameID.String()),
+				zap.String("client_id", client.ID.String()))
 		}
 	}
 }
 
+// SpectatorCount returns the number of spectators of a game connected to this instance
+func (h *Hub) SpectatorCount(gameID uuid.UUID) int {
+	h.mutex.RLock()
+	room, exists := h.gameRooms[gameID]
+	h.mutex.RUnlock()
+
+	if !exists {
+		return 0
+	}
+
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+
+	count := 0
+	for _, client := range room.Clients {
+		if client.Spectator {
+			count++
+		}
+	}
+	return count
+}
+
+// IsConnected reports whether the user has a player connection open, to any instance sharing the hub's connections;
+// spectating does not count
+func (h *Hub) IsConnected(userID uuid.UUID) bool {
+	return h.connections.IsConnected(userID)
+}
+
+// SendToUser sends a message to every player connection of the user, so each of their tabs or devices
+// receives the same sequence of game updates
 func (h *Hub) SendToUser(userID uuid.UUID, message []byte) {
+	h.backend.Publish(Envelope{Target: TargetUser, ID: userID, Message: message})
+}
+
+func (h *Hub) sendToUser(userID uuid.UUID, message []byte) {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 
 	for _, client := range h.clients {
-		if client.UserID == userID {
+		if client.UserID == userID && !client.Spectator {
 			select {
 			case client.Send <- message:
 			default:
-				close(client.Send)
-				delete(h.clients, client.ID)
+				logSkipped(client)
 			}
-			return
 		}
 	}
 }
@@ -210,9 +421,18 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 16 * 1024
 )
 
+// logSkipped records a message that was not sent because the client's send buffer is full.
+// The send channel is only closed when the client unregisters, so replies to its requests can never hit a closed channel;
+// a client that fell behind can ask for a resync.
+func logSkipped(client *Client) {
+	logger.Debug("Client send buffer full, skipping message",
+		zap.String("client_id", client.ID.String()),
+		zap.String("user_id", client.UserID.String()))
+}
+
 func (c *Client) readPump() {
 	defer func() {
 		c.Hub.unregister <- c
@@ -223,6 +443,9 @@ func (c *Client) readPump() {
 	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.Conn.SetPongHandler(func(string) error {
 		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		if !c.Spectator {
+			c.Hub.connections.Refresh(c.UserID)
+		}
 		return nil
 	})
 
@@ -235,13 +458,14 @@ func (c *Client) readPump() {
 			break
 		}
 
-		var msg Message
-		if err := json.Unmarshal(message, &msg); err != nil {
+		var request Request
+		if err := json.Unmarshal(message, &request); err != nil {
 			logger.Error("Invalid message format", zap.Error(err))
+			c.replyError(&request, fmt.Errorf("invalid message format"))
 			continue
 		}
 
-		c.handleMessage(&msg)
+		c.handleMessage(&request)
 	}
 }
 
@@ -285,6 +509,3 @@ func (c *Client) writePump() {
 		}
 	}
 }
-
-func (c *Client) handleMessage(msg *Message) {
-}
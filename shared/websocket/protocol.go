@@ -0,0 +1,152 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ua/shared/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Requests clients send over the socket
+const (
+	RequestJoinGame        = "join_game"
+	RequestLeaveGame       = "leave_game"
+	RequestSubmitAction    = "submit_action"
+	RequestMulligan        = "mulligan"
+	RequestResolveDecision = "resolve_decision"
+	RequestPing            = "ping"
+	RequestResync          = "resync"
+)
+
+// Replies to a request, carrying its request ID
+const (
+	MessageTypeAck   = "ack"
+	MessageTypeError = "error"
+	MessageTypePong  = "pong"
+)
+
+// requestTimeout bounds how long one request may take
+const requestTimeout = 30 * time.Second
+
+// Request is a message sent by a client. The request ID is echoed in the reply so the client can match them up.
+// Game requests without a game ID apply to the game the client joined last.
+type Request struct {
+	Type      string          `json:"type"`
+	RequestID string          `json:"request_id,omitempty"`
+	GameID    *uuid.UUID      `json:"game_id,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+type SubmitActionPayload struct {
+	ActionType string          `json:"action_type"`
+	ActionData json.RawMessage `json:"action_data,omitempty"`
+}
+
+type MulliganPayload struct {
+	Mulligan bool `json:"mulligan"`
+}
+
+type ResolveDecisionPayload struct {
+	DecisionID uuid.UUID `json:"decision_id"`
+	Choices    []string  `json:"choices"`
+}
+
+type ErrorPayload struct {
+	Error string `json:"error"`
+}
+
+type PongPayload struct {
+	ServerTime time.Time `json:"server_time"`
+}
+
+// RequestHandler runs the game requests of a player: join_game, submit_action, mulligan, resolve_decision and resync.
+// The request always has a game ID. The returned value is sent back in the ack; an error is sent back as an error reply.
+// join_game must fail for users who do not play in the game, since the client joins the game room once it succeeds.
+type RequestHandler interface {
+	HandleRequest(ctx context.Context, userID uuid.UUID, request *Request) (interface{}, error)
+}
+
+// SetRequestHandler sets the handler of game requests. Call it before Run.
+func (h *Hub) SetRequestHandler(handler RequestHandler) {
+	h.requests = handler
+}
+
+func (c *Client) handleMessage(request *Request) {
+	switch request.Type {
+	case RequestPing:
+		c.reply(MessageTypePong, request, PongPayload{ServerTime: time.Now()})
+
+	case RequestLeaveGame:
+		c.Hub.LeaveGameRoom(c.ID)
+		c.reply(MessageTypeAck, request, nil)
+
+	case RequestJoinGame, RequestSubmitAction, RequestMulligan, RequestResolveDecision, RequestResync:
+		if c.Spectator {
+			c.replyError(request, fmt.Errorf("spectators cannot send game requests"))
+			return
+		}
+		if request.GameID == nil {
+			request.GameID = c.Hub.clientGame(c.ID)
+		}
+		if request.GameID == nil {
+			c.replyError(request, fmt.Errorf("game_id is required"))
+			return
+		}
+		if c.Hub.requests == nil {
+			c.replyError(request, fmt.Errorf("game requests are not supported"))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		result, err := c.Hub.requests.HandleRequest(ctx, c.UserID, request)
+		if err != nil {
+			c.replyError(request, err)
+			return
+		}
+		if request.Type == RequestJoinGame {
+			c.Hub.JoinGameRoom(*request.GameID, c.ID)
+		}
+		c.reply(MessageTypeAck, request, result)
+
+	default:
+		c.replyError(request, fmt.Errorf("unknown message type: %s", request.Type))
+	}
+}
+
+func (c *Client) replyError(request *Request, err error) {
+	logger.Debug("WebSocket request failed",
+		zap.String("client_id", c.ID.String()),
+		zap.String("type", request.Type),
+		zap.String("request_id", request.RequestID),
+		zap.Error(err))
+	c.reply(MessageTypeError, request, ErrorPayload{Error: err.Error()})
+}
+
+// reply sends the client the answer to one of its requests. A reply that does not fit in the send buffer is dropped.
+func (c *Client) reply(messageType string, request *Request, payload interface{}) {
+	message, err := json.Marshal(Message{
+		Type:      messageType,
+		Payload:   payload,
+		GameID:    request.GameID,
+		RequestID: request.RequestID,
+	})
+	if err != nil {
+		logger.Error("Failed to serialize reply", zap.Error(err))
+		return
+	}
+
+	select {
+	case c.Send <- message:
+	default:
+		logger.Debug("Client send buffer full, dropping reply",
+			zap.String("client_id", c.ID.String()),
+			zap.String("request_id", request.RequestID))
+	}
+}